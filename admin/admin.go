@@ -0,0 +1,292 @@
+package logadmin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/paularlott/logger"
+)
+
+// Handler is an http.Handler for viewing and changing log levels on a running service.
+//
+//	GET    returns the current levels
+//	PUT    sets the global level, or a group level if "group" is given, optionally reverting after "duration"
+//	DELETE removes the level override for the group named by the "group" query parameter
+//
+// The logger must implement logger.LevelSetter, and logger.GroupLevelSetter for group levels.
+type Handler struct {
+	log     logger.Logger
+	mu      sync.Mutex
+	reverts map[string]*revert // Keyed by group, "" for the global level
+}
+
+// revert restores a level when its timer fires
+type revert struct {
+	timer    *time.Timer
+	at       time.Time
+	previous string // Level to restore, "" to clear a group override
+}
+
+// LevelRequest is the body accepted by PUT
+type LevelRequest struct {
+	Level    string `json:"level"`              // "trace", "debug", "info", "warn", "error"
+	Group    string `json:"group,omitempty"`    // Group name used with WithGroup, empty for the global level
+	Duration string `json:"duration,omitempty"` // Revert to the previous level after this long, e.g. "10m"
+}
+
+// LevelResponse is the body returned by all methods
+type LevelResponse struct {
+	Level         string               `json:"level"`
+	Groups        map[string]string    `json:"groups,omitempty"`
+	RevertAt      *time.Time           `json:"revert_at,omitempty"`
+	GroupRevertAt map[string]time.Time `json:"group_revert_at,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler creates a new Handler controlling the levels of l and all of its children
+func NewHandler(l logger.Logger) *Handler {
+	return &Handler{
+		log:     l,
+		reverts: make(map[string]*revert),
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.log.(logger.LevelSetter); !ok {
+		writeJSON(w, http.StatusNotImplemented, errorResponse{Error: "logger does not support runtime level changes"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		writeJSON(w, http.StatusOK, h.state())
+
+	case http.MethodPut:
+		var req LevelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body: " + err.Error()})
+			return
+		}
+
+		var duration time.Duration
+		if req.Duration != "" {
+			d, err := time.ParseDuration(req.Duration)
+			if err != nil || d <= 0 {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid duration %q", req.Duration)})
+				return
+			}
+			duration = d
+		}
+
+		if err := h.SetLevel(req.Group, req.Level, duration); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, h.state())
+
+	case http.MethodDelete:
+		group := r.URL.Query().Get("group")
+		if group == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "group query parameter is required"})
+			return
+		}
+		if err := h.ClearGroupLevel(group); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, h.state())
+
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+	}
+}
+
+// SetLevel sets the level for the group, or the global level if group is empty.
+// If duration is greater than zero the previous level is restored once it has elapsed.
+func (h *Handler) SetLevel(group, level string, duration time.Duration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.set(group, level, duration)
+}
+
+// set applies the level, logging the change, and schedules the revert, the caller must hold mu
+func (h *Handler) set(group, level string, duration time.Duration, keysAndValues ...any) error {
+	previous, err := h.current(group)
+	if err != nil {
+		return err
+	}
+	if _, err := logger.ParseLevel(level); err != nil {
+		return err
+	}
+
+	fields := []any{"target", target(group), "log_level", level}
+	if duration > 0 {
+		fields = append(fields, "duration", duration.String())
+	}
+	err = h.logChange("log level changed", append(fields, keysAndValues...), func() error {
+		return h.apply(group, level)
+	})
+	if err != nil {
+		return err
+	}
+
+	// A pending revert keeps the level from before the first temporary change
+	if pending, ok := h.reverts[group]; ok {
+		pending.timer.Stop()
+		delete(h.reverts, group)
+		previous = pending.previous
+	}
+
+	if duration > 0 {
+		rv := &revert{
+			at:       time.Now().Add(duration),
+			previous: previous,
+		}
+		rv.timer = time.AfterFunc(duration, func() { h.revert(group, rv) })
+		h.reverts[group] = rv
+	}
+	return nil
+}
+
+// ClearGroupLevel removes the override for the group so it follows the global level again
+func (h *Handler) ClearGroupLevel(group string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	gs, ok := h.log.(logger.GroupLevelSetter)
+	if !ok {
+		return fmt.Errorf("logger does not support group levels")
+	}
+
+	if pending, ok := h.reverts[group]; ok {
+		pending.timer.Stop()
+		delete(h.reverts, group)
+	}
+	gs.ClearGroupLevel(group)
+
+	h.log.Info("log level override removed", "target", target(group))
+	return nil
+}
+
+// revert restores the level saved in rv, unless it has since been superseded
+func (h *Handler) revert(group string, rv *revert) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.reverts[group] != rv {
+		return
+	}
+	h.restore(group, rv)
+}
+
+// restore removes the pending revert and restores its level, the caller must hold mu
+func (h *Handler) restore(group string, rv *revert, keysAndValues ...any) {
+	rv.timer.Stop()
+	delete(h.reverts, group)
+
+	if rv.previous == "" {
+		h.logChange("log level override expired", append([]any{"target", target(group)}, keysAndValues...), func() error {
+			if gs, ok := h.log.(logger.GroupLevelSetter); ok {
+				gs.ClearGroupLevel(group)
+			}
+			return nil
+		})
+		return
+	}
+
+	err := h.logChange("log level reverted", append([]any{"target", target(group), "log_level", rv.previous}, keysAndValues...), func() error {
+		return h.apply(group, rv.previous)
+	})
+	if err != nil {
+		h.log.WithError(err).Error("failed to revert log level", "target", target(group))
+	}
+}
+
+// logChange calls apply and writes an Info entry for the change. The entry is written
+// first if the current level allows it, so raising the level doesn't hide its own
+// entry, otherwise once the change is applied so lowering the level is recorded too.
+func (h *Handler) logChange(msg string, keysAndValues []any, apply func() error) error {
+	before := logger.IsEnabled(h.log, logger.LevelInfo)
+	if before {
+		h.log.Info(msg, keysAndValues...)
+	}
+	if err := apply(); err != nil {
+		return err
+	}
+	if !before {
+		h.log.Info(msg, keysAndValues...)
+	}
+	return nil
+}
+
+// current returns the level for the group, "" if the group has no override
+func (h *Handler) current(group string) (string, error) {
+	if group == "" {
+		return h.log.(logger.LevelSetter).Level(), nil
+	}
+
+	gs, ok := h.log.(logger.GroupLevelSetter)
+	if !ok {
+		return "", fmt.Errorf("logger does not support group levels")
+	}
+	return gs.GroupLevels()[group], nil
+}
+
+func (h *Handler) apply(group, level string) error {
+	if group == "" {
+		return h.log.(logger.LevelSetter).SetLevel(level)
+	}
+
+	gs, ok := h.log.(logger.GroupLevelSetter)
+	if !ok {
+		return fmt.Errorf("logger does not support group levels")
+	}
+	return gs.SetGroupLevel(group, level)
+}
+
+func (h *Handler) state() LevelResponse {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	resp := LevelResponse{
+		Level: h.log.(logger.LevelSetter).Level(),
+	}
+	if gs, ok := h.log.(logger.GroupLevelSetter); ok {
+		resp.Groups = gs.GroupLevels()
+	}
+
+	for group, rv := range h.reverts {
+		if group == "" {
+			at := rv.at
+			resp.RevertAt = &at
+			continue
+		}
+		if resp.GroupRevertAt == nil {
+			resp.GroupRevertAt = make(map[string]time.Time)
+		}
+		resp.GroupRevertAt[group] = rv.at
+	}
+
+	return resp
+}
+
+func target(group string) string {
+	if group == "" {
+		return "global"
+	}
+	return group
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
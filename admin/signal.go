@@ -0,0 +1,45 @@
+package logadmin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/paularlott/logger"
+)
+
+const defaultDebugDuration = 15 * time.Minute
+
+// checkDebugLevel reports whether DebugOnSignal can switch the logger to level
+func (h *Handler) checkDebugLevel(level string) error {
+	if _, ok := h.log.(logger.LevelSetter); !ok {
+		return fmt.Errorf("logger does not support runtime level changes")
+	}
+	_, err := logger.ParseLevel(level)
+	return err
+}
+
+// toggleDebug switches the global level to level for duration, or reverts it early
+// if a temporary level is already in place
+func (h *Handler) toggleDebug(signal, level string, duration time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if pending, ok := h.reverts[""]; ok {
+		h.restore("", pending, "signal", signal)
+		return
+	}
+
+	if err := h.set("", level, duration, "signal", signal); err != nil {
+		h.log.WithError(err).Error("failed to change log level", "target", target(""), "signal", signal)
+	}
+}
+
+// revertDebug restores the global level if a temporary level is in place
+func (h *Handler) revertDebug(signal string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if pending, ok := h.reverts[""]; ok {
+		h.restore("", pending, "signal", signal)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package logadmin
+
+import "time"
+
+// DebugOnSignal is not supported on this platform, SIGUSR1 and SIGUSR2 don't exist.
+// It logs a warning and returns a function that does nothing.
+func (h *Handler) DebugOnSignal(level string, duration time.Duration) (func(), error) {
+	if err := h.checkDebugLevel(level); err != nil {
+		return nil, err
+	}
+
+	h.log.Warn("debug mode signals are not supported on this platform")
+	return func() {}, nil
+}
@@ -0,0 +1,50 @@
+//go:build unix
+
+package logadmin
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// DebugOnSignal lets the global level be raised without a restart: SIGUSR1 switches
+// it to level, e.g. "debug" or "trace", for duration, and a second SIGUSR1 or a SIGUSR2
+// reverts to the previous level early. Each transition is logged. The duration
+// defaults to 15 minutes. It returns a function that stops listening for the signals.
+func (h *Handler) DebugOnSignal(level string, duration time.Duration) (func(), error) {
+	if err := h.checkDebugLevel(level); err != nil {
+		return nil, err
+	}
+	if duration <= 0 {
+		duration = defaultDebugDuration
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case sig := <-sigs:
+				if sig == syscall.SIGUSR1 {
+					h.toggleDebug("SIGUSR1", level, duration)
+				} else {
+					h.revertDebug("SIGUSR2")
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigs)
+		close(stop)
+		<-done
+	}, nil
+}
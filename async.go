@@ -0,0 +1,317 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultAsyncQueueSize = 1024
+
+// OverflowPolicy controls what an AsyncLogger does with an entry when its queue is full
+type OverflowPolicy int
+
+const (
+	OverflowBlock          OverflowPolicy = iota // Wait for room in the queue
+	OverflowDropNewest                           // Drop the entry being written
+	OverflowDropOldest                           // Drop the oldest queued entry to make room
+	OverflowDropBelowLevel                       // Drop the entry if below AsyncConfig.MinLevel, otherwise wait
+)
+
+// AsyncConfig for creating a new AsyncLogger
+type AsyncConfig struct {
+	QueueSize int            // Entries that can be waiting to be written, defaults to 1024
+	Overflow  OverflowPolicy // What to do when the queue is full, defaults to OverflowBlock
+	MinLevel  Level          // With OverflowDropBelowLevel, entries at this level or above are never dropped
+}
+
+// AsyncStats holds the counters of an AsyncLogger
+type AsyncStats struct {
+	Written uint64
+	Dropped uint64
+}
+
+// AsyncLogger wraps a Logger and writes entries from a background goroutine, so callers
+// aren't held up by slow output. Call Flush to wait for queued entries to be written and
+// Close on shutdown; Fatal flushes before exiting. Lazy values are evaluated when the
+// entry is written. Child loggers share the queue of the logger they came from.
+type AsyncLogger struct {
+	logger Logger
+	state  *asyncState
+}
+
+type asyncState struct {
+	queue    chan asyncEntry
+	overflow OverflowPolicy
+	minLevel Level
+	closeMu  sync.RWMutex // Held for reading while sending so Close can't close the queue under a sender
+	closed   bool
+	queued   atomic.Uint64 // Entries accepted into the queue
+	written  atomic.Uint64
+	dropped  atomic.Uint64
+	mu       sync.Mutex    // Guards removed and progress
+	removed  uint64        // Entries taken off the queue, whether written or dropped
+	progress chan struct{} // Closed when removed advances, created by waiting flushes
+	done     chan struct{}
+}
+
+type asyncEntry struct {
+	logger        Logger
+	ctx           context.Context
+	level         Level
+	msg           string
+	keysAndValues []any
+}
+
+// NewAsyncLogger creates a new AsyncLogger around l and starts its background goroutine
+func NewAsyncLogger(l Logger, cfg AsyncConfig) *AsyncLogger {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultAsyncQueueSize
+	}
+
+	state := &asyncState{
+		queue:    make(chan asyncEntry, cfg.QueueSize),
+		overflow: cfg.Overflow,
+		minLevel: cfg.MinLevel,
+		done:     make(chan struct{}),
+	}
+	go state.writeLoop()
+
+	return &AsyncLogger{
+		logger: l,
+		state:  state,
+	}
+}
+
+// Stats returns the number of entries written and dropped
+func (a *AsyncLogger) Stats() AsyncStats {
+	return AsyncStats{
+		Written: a.state.written.Load(),
+		Dropped: a.state.dropped.Load(),
+	}
+}
+
+// Flush waits until the entries queued before the call have been written, or ctx is done
+func (a *AsyncLogger) Flush(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	target := a.state.queued.Load()
+	for {
+		a.state.mu.Lock()
+		if a.state.removed >= target {
+			a.state.mu.Unlock()
+			flush(ctx, a.logger)
+			return nil
+		}
+		if a.state.progress == nil {
+			a.state.progress = make(chan struct{})
+		}
+		progress := a.state.progress
+		a.state.mu.Unlock()
+
+		select {
+		case <-progress:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Close writes any queued entries and stops the background goroutine. Entries written
+// after Close are written directly to the wrapped logger.
+func (a *AsyncLogger) Close() error {
+	a.state.closeMu.Lock()
+	if a.state.closed {
+		a.state.closeMu.Unlock()
+		return nil
+	}
+	a.state.closed = true
+	close(a.state.queue)
+	a.state.closeMu.Unlock()
+
+	<-a.state.done
+	return nil
+}
+
+func (st *asyncState) writeLoop() {
+	defer close(st.done)
+
+	for e := range st.queue {
+		LogAt(e.logger, e.ctx, e.level, e.msg, e.keysAndValues...)
+		st.written.Add(1)
+		st.remove()
+	}
+}
+
+// remove records that an entry has left the queue and wakes any waiting flushes
+func (st *asyncState) remove() {
+	st.mu.Lock()
+	st.removed++
+	if st.progress != nil {
+		close(st.progress)
+		st.progress = nil
+	}
+	st.mu.Unlock()
+}
+
+// enqueue adds an entry to the queue according to the overflow policy, reporting
+// false if the queue has been closed
+func (st *asyncState) enqueue(e asyncEntry) bool {
+	st.closeMu.RLock()
+	defer st.closeMu.RUnlock()
+
+	if st.closed {
+		return false
+	}
+
+	st.queued.Add(1)
+	select {
+	case st.queue <- e:
+		return true
+	default:
+	}
+
+	switch {
+	case st.overflow == OverflowDropNewest,
+		st.overflow == OverflowDropBelowLevel && e.level < st.minLevel:
+		st.dropped.Add(1)
+		st.remove()
+		return true
+
+	case st.overflow == OverflowDropOldest:
+		for {
+			select {
+			case st.queue <- e:
+				return true
+			default:
+			}
+			select {
+			case <-st.queue:
+				st.dropped.Add(1)
+				st.remove()
+			default:
+			}
+		}
+	}
+
+	st.queue <- e
+	return true
+}
+
+func (a *AsyncLogger) Trace(msg string, keysAndValues ...any) {
+	a.Log(nil, LevelTrace, msg, keysAndValues...)
+}
+
+func (a *AsyncLogger) Debug(msg string, keysAndValues ...any) {
+	a.Log(nil, LevelDebug, msg, keysAndValues...)
+}
+
+func (a *AsyncLogger) Info(msg string, keysAndValues ...any) {
+	a.Log(nil, LevelInfo, msg, keysAndValues...)
+}
+
+func (a *AsyncLogger) Warn(msg string, keysAndValues ...any) {
+	a.Log(nil, LevelWarn, msg, keysAndValues...)
+}
+
+func (a *AsyncLogger) Error(msg string, keysAndValues ...any) {
+	a.Log(nil, LevelError, msg, keysAndValues...)
+}
+
+func (a *AsyncLogger) Fatal(msg string, keysAndValues ...any) {
+	a.Flush(context.Background())
+	a.logger.Fatal(msg, keysAndValues...)
+}
+
+func (a *AsyncLogger) TraceContext(ctx context.Context, msg string, keysAndValues ...any) {
+	a.Log(ctx, LevelTrace, msg, keysAndValues...)
+}
+
+func (a *AsyncLogger) DebugContext(ctx context.Context, msg string, keysAndValues ...any) {
+	a.Log(ctx, LevelDebug, msg, keysAndValues...)
+}
+
+func (a *AsyncLogger) InfoContext(ctx context.Context, msg string, keysAndValues ...any) {
+	a.Log(ctx, LevelInfo, msg, keysAndValues...)
+}
+
+func (a *AsyncLogger) WarnContext(ctx context.Context, msg string, keysAndValues ...any) {
+	a.Log(ctx, LevelWarn, msg, keysAndValues...)
+}
+
+func (a *AsyncLogger) ErrorContext(ctx context.Context, msg string, keysAndValues ...any) {
+	a.Log(ctx, LevelError, msg, keysAndValues...)
+}
+
+func (a *AsyncLogger) FatalContext(ctx context.Context, msg string, keysAndValues ...any) {
+	a.Flush(context.Background())
+	if cl, ok := a.logger.(ContextLogger); ok {
+		cl.FatalContext(ctx, msg, keysAndValues...)
+		return
+	}
+	a.logger.Fatal(msg, keysAndValues...)
+}
+
+// Log queues an entry at the given level, without exiting for LevelFatal
+func (a *AsyncLogger) Log(ctx context.Context, level Level, msg string, keysAndValues ...any) {
+	if !IsEnabled(a.logger, level) {
+		return
+	}
+
+	// The caller may reuse its slice once we return, and the backend needs the call
+	// site recorded now to report where the entry was logged from
+	e := asyncEntry{
+		logger:        a.logger,
+		ctx:           withCallSite(ctx),
+		level:         level,
+		msg:           msg,
+		keysAndValues: append([]any(nil), keysAndValues...),
+	}
+	if !a.state.enqueue(e) {
+		LogAt(a.logger, ctx, level, msg, keysAndValues...)
+	}
+}
+
+// Enabled reports whether the wrapped logger would write an entry at the given level
+func (a *AsyncLogger) Enabled(level Level) bool {
+	return IsEnabled(a.logger, level)
+}
+
+// SetLevel sets the level of the wrapped logger if it implements LevelSetter
+func (a *AsyncLogger) SetLevel(level string) error {
+	return setLevel(a.logger, level)
+}
+
+// Level returns the level of the wrapped logger
+func (a *AsyncLogger) Level() string {
+	return levelOf(a.logger)
+}
+
+// SetGroupLevel sets the level for the group on the wrapped logger if it implements GroupLevelSetter
+func (a *AsyncLogger) SetGroupLevel(group, level string) error {
+	return setGroupLevel(a.logger, group, level)
+}
+
+// ClearGroupLevel removes the override for the group from the wrapped logger
+func (a *AsyncLogger) ClearGroupLevel(group string) {
+	clearGroupLevel(a.logger, group)
+}
+
+// GroupLevels returns the group overrides of the wrapped logger
+func (a *AsyncLogger) GroupLevels() map[string]string {
+	return groupLevelsOf(a.logger)
+}
+
+func (a *AsyncLogger) With(key string, value any) Logger {
+	return &AsyncLogger{logger: a.logger.With(key, value), state: a.state}
+}
+
+func (a *AsyncLogger) WithError(err error) Logger {
+	return &AsyncLogger{logger: a.logger.WithError(err), state: a.state}
+}
+
+func (a *AsyncLogger) WithGroup(group string) Logger {
+	return &AsyncLogger{logger: a.logger.WithGroup(group), state: a.state}
+}
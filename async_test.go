@@ -0,0 +1,244 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/paularlott/logger"
+	logtesting "github.com/paularlott/logger/testing"
+)
+
+// gatedLogger holds every entry until release is closed, so tests can fill the queue
+type gatedLogger struct {
+	*logtesting.MockLogger
+	started chan struct{} // Receives once per entry the writer starts on
+	release chan struct{}
+}
+
+func newGatedLogger() *gatedLogger {
+	return &gatedLogger{
+		MockLogger: logtesting.New(),
+		started:    make(chan struct{}, 100),
+		release:    make(chan struct{}),
+	}
+}
+
+func (g *gatedLogger) Log(ctx context.Context, level logger.Level, msg string, keysAndValues ...any) {
+	g.started <- struct{}{}
+	<-g.release
+	g.MockLogger.Log(ctx, level, msg, keysAndValues...)
+}
+
+func (g *gatedLogger) messages() []string {
+	var msgs []string
+	for _, e := range g.GetEntries() {
+		msgs = append(msgs, e.Message)
+	}
+	return msgs
+}
+
+// fill writes entry "0", waits for the writer to pick it up, then queues "1" and "2"
+// so a queue of size 2 is full
+func fill(t *testing.T, a *logger.AsyncLogger, g *gatedLogger) {
+	t.Helper()
+	a.Info("0")
+	select {
+	case <-g.started:
+	case <-time.After(time.Second):
+		t.Fatal("writer didn't start on the first entry")
+	}
+	a.Info("1")
+	a.Info("2")
+}
+
+// returns runs fn in a goroutine, returning a channel closed once it returns
+func returns(fn func()) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	return done
+}
+
+func assertBlocked(t *testing.T, done <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-done:
+		t.Fatalf("%s returned while the writer was held", what)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func assertReturns(t *testing.T, done <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("%s didn't return", what)
+	}
+}
+
+func assertMessages(t *testing.T, g *gatedLogger, want ...string) {
+	t.Helper()
+	if got := g.messages(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got entries %v, want %v", got, want)
+	}
+}
+
+func assertStats(t *testing.T, a *logger.AsyncLogger, written, dropped uint64) {
+	t.Helper()
+	if got := a.Stats(); got.Written != written || got.Dropped != dropped {
+		t.Errorf("got stats %+v, want written %d dropped %d", got, written, dropped)
+	}
+}
+
+func TestAsyncOverflowBlock(t *testing.T) {
+	g := newGatedLogger()
+	a := logger.NewAsyncLogger(g, logger.AsyncConfig{QueueSize: 2, Overflow: logger.OverflowBlock})
+	defer a.Close()
+
+	fill(t, a, g)
+	logged := returns(func() { a.Info("3") })
+	assertBlocked(t, logged, "Info on a full queue")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := a.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Flush while held returned %v, want a deadline error", err)
+	}
+
+	close(g.release)
+	assertReturns(t, logged, "Info")
+	if err := a.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertMessages(t, g, "0", "1", "2", "3")
+	assertStats(t, a, 4, 0)
+}
+
+func TestAsyncOverflowDropNewest(t *testing.T) {
+	g := newGatedLogger()
+	a := logger.NewAsyncLogger(g, logger.AsyncConfig{QueueSize: 2, Overflow: logger.OverflowDropNewest})
+	defer a.Close()
+
+	fill(t, a, g)
+	a.Info("3")
+	a.Info("4")
+
+	flushed := returns(func() { a.Flush(context.Background()) })
+	assertBlocked(t, flushed, "Flush")
+	close(g.release)
+	assertReturns(t, flushed, "Flush")
+
+	assertMessages(t, g, "0", "1", "2")
+	assertStats(t, a, 3, 2)
+}
+
+func TestAsyncOverflowDropOldest(t *testing.T) {
+	g := newGatedLogger()
+	a := logger.NewAsyncLogger(g, logger.AsyncConfig{QueueSize: 2, Overflow: logger.OverflowDropOldest})
+	defer a.Close()
+
+	fill(t, a, g)
+	a.Info("3")
+	a.Info("4")
+
+	// Flush must wait for the entries that replaced the dropped ones
+	flushed := returns(func() { a.Flush(context.Background()) })
+	assertBlocked(t, flushed, "Flush")
+	close(g.release)
+	assertReturns(t, flushed, "Flush")
+
+	assertMessages(t, g, "0", "3", "4")
+	assertStats(t, a, 3, 2)
+}
+
+func TestAsyncOverflowDropBelowLevel(t *testing.T) {
+	g := newGatedLogger()
+	a := logger.NewAsyncLogger(g, logger.AsyncConfig{
+		QueueSize: 2,
+		Overflow:  logger.OverflowDropBelowLevel,
+		MinLevel:  logger.LevelWarn,
+	})
+	defer a.Close()
+
+	fill(t, a, g)
+	a.Debug("3")
+	logged := returns(func() { a.Error("4") })
+	assertBlocked(t, logged, "Error on a full queue")
+
+	close(g.release)
+	assertReturns(t, logged, "Error")
+	if err := a.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertMessages(t, g, "0", "1", "2", "4")
+	assertStats(t, a, 4, 1)
+}
+
+// TestAsyncConcurrent logs from many goroutines, flushing as it goes, and checks every
+// entry is accounted for once a final Flush returns
+func TestAsyncConcurrent(t *testing.T) {
+	policies := map[string]logger.OverflowPolicy{
+		"block":       logger.OverflowBlock,
+		"drop newest": logger.OverflowDropNewest,
+		"drop oldest": logger.OverflowDropOldest,
+		"drop below":  logger.OverflowDropBelowLevel,
+	}
+
+	for name, policy := range policies {
+		t.Run(name, func(t *testing.T) {
+			mock := logtesting.New()
+			a := logger.NewAsyncLogger(mock, logger.AsyncConfig{
+				QueueSize: 4,
+				Overflow:  policy,
+				MinLevel:  logger.LevelWarn,
+			})
+			defer a.Close()
+
+			const goroutines, perGoroutine = 8, 200
+			var wg sync.WaitGroup
+			for i := 0; i < goroutines; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					kv := []any{"n", 0}
+					for j := 0; j < perGoroutine; j++ {
+						kv[1] = j // Reusing the slice must not affect queued entries
+						if j%2 == 0 {
+							a.Info("info", kv...)
+						} else {
+							a.Warn("warn", kv...)
+						}
+						if j%50 == 0 {
+							a.Flush(context.Background())
+						}
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			if err := a.Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			stats := a.Stats()
+			if total := stats.Written + stats.Dropped; total != goroutines*perGoroutine {
+				t.Errorf("written %d + dropped %d = %d, want %d", stats.Written, stats.Dropped, total, goroutines*perGoroutine)
+			}
+			if got := uint64(len(mock.GetEntries())); got != stats.Written {
+				t.Errorf("%d entries written to the logger, stats say %d", got, stats.Written)
+			}
+			if policy == logger.OverflowBlock && stats.Dropped != 0 {
+				t.Errorf("dropped %d entries with OverflowBlock", stats.Dropped)
+			}
+			if policy == logger.OverflowDropBelowLevel && mock.CountEntries("warn") != goroutines*perGoroutine/2 {
+				t.Errorf("got %d warn entries, want all %d", mock.CountEntries("warn"), goroutines*perGoroutine/2)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"context"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	CallerKey = "caller" // Key of the short caller in console output, e.g. "server/http.go:42"
+	SourceKey = "source" // Key of the caller in JSON output, a StackFrame object
+)
+
+// SourceConfig controls the caller and stack trace a backend adds to entries, built
+// from Config.AddCaller, Config.CallerSkip and Config.StackTrace
+type SourceConfig struct {
+	Caller bool // Add the code that made the log call
+	Stack  bool // Add a stack trace to error and fatal entries
+	Skip   int  // Extra frames to skip when the application wraps the logger
+	JSON   bool // Add the caller under SourceKey rather than as a short string under CallerKey
+}
+
+// callerSkipPrefixes are the packages whose frames are never reported as the caller:
+// this package's wrappers and package-level functions, the backends, the standard
+// library loggers that the bridges forward from, and the runtime
+var callerSkipPrefixes = []string{
+	"github.com/paularlott/logger.",
+	"github.com/paularlott/logger/slog.",
+	"github.com/paularlott/logger/zerolog.",
+	"log.",
+	"log/slog.",
+	"runtime.",
+}
+
+// callSiteKey is the context key of the program counters recorded by withCallSite
+type callSiteKey struct{}
+
+// withCallSite returns a copy of ctx carrying the stack of the current goroutine, so an
+// entry written later on another goroutine, as AsyncLogger does, still reports where it
+// was logged from
+func withCallSite(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, callSiteKey{}, callSite(nil))
+}
+
+// callSite returns the program counters recorded in ctx by withCallSite, otherwise
+// those of the current goroutine
+func callSite(ctx context.Context) []uintptr {
+	if ctx != nil {
+		if pcs, ok := ctx.Value(callSiteKey{}).([]uintptr); ok {
+			return pcs
+		}
+	}
+	pcs := make([]uintptr, maxStackDepth)
+	return pcs[:runtime.Callers(2, pcs)]
+}
+
+// Caller returns the frame of the code that called into the logger, for backends that
+// record the source of each entry. Frames inside the logging packages are passed over,
+// then skip more frames for applications that wrap the logger in their own package.
+// It reports false if no such frame is found.
+func Caller(skip int) (runtime.Frame, bool) {
+	return callerFrom(callSite(nil), skip)
+}
+
+// callerFrom returns the frame Caller would for the given program counters
+func callerFrom(pcs []uintptr, skip int) (runtime.Frame, bool) {
+	// Frames rather than raw program counters so calls inlined into the caller are seen
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !isLoggerFrame(frame.Function) {
+			if skip <= 0 {
+				return frame, true
+			}
+			skip--
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// Fields returns the key-value pairs for the caller and stack trace of an entry at the
+// given level. ctx is the entry's context, which may be nil, and err is the error from
+// WithError, which is checked for a recorded stack along with any errors in keysAndValues.
+func (c *SourceConfig) Fields(ctx context.Context, level Level, err error, keysAndValues []any) []any {
+	var fields []any
+	if c.Caller {
+		if frame, ok := callerFrom(callSite(ctx), c.Skip); ok {
+			if c.JSON {
+				fields = append(fields, SourceKey, toStackFrame(frame))
+			} else {
+				fields = append(fields, CallerKey, ShortCaller(frame))
+			}
+		}
+	}
+	if c.Stack && level >= LevelError {
+		if stack := StackFor(ctx, err, c.Skip, keysAndValues); stack != nil {
+			fields = append(fields, StackKey, stack)
+		}
+	}
+	return fields
+}
+
+// ShortCaller formats a frame as the file's directory and name with the line, e.g. "server/http.go:42"
+func ShortCaller(frame runtime.Frame) string {
+	return path.Base(path.Dir(frame.File)) + "/" + path.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+}
+
+func isLoggerFrame(function string) bool {
+	for _, prefix := range callerSkipPrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
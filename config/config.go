@@ -0,0 +1,357 @@
+package logconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/paularlott/logger"
+	_ "github.com/paularlott/logger/slog" // Registers the default backend
+)
+
+const defaultPollInterval = 5 * time.Second
+
+// File is the JSON layout of a logging config file, e.g.
+//
+//	{
+//	  "level": "info",
+//	  "level_overrides": {"database": "trace"},
+//	  "format": "console",
+//	  "sinks": [
+//	    {"output": "stdout"},
+//	    {"output": "/var/log/app.json", "format": "json", "level": "debug"}
+//	  ]
+//	}
+type File struct {
+	Backend        string            `json:"backend,omitempty"`         // Registered backend name, defaults to "slog"
+	Level          string            `json:"level,omitempty"`           // "trace", "debug", "info", "warn", "error", defaults to "info"
+	LevelOverrides map[string]string `json:"level_overrides,omitempty"` // Levels for loggers created with WithGroup, keyed by group name
+	Format         string            `json:"format,omitempty"`          // "console" or "json"
+	GroupFieldName string            `json:"group_field,omitempty"`     // Field name for groups
+	NoColor        bool              `json:"no_color,omitempty"`        // Disable colors in console output
+	Sinks          []Sink            `json:"sinks,omitempty"`           // Outputs, defaults to a single sink writing to stdout
+}
+
+// Sink is one output of the logger
+type Sink struct {
+	Output string `json:"output,omitempty"` // "stdout", "stderr" or the path of a file to append to
+	Format string `json:"format,omitempty"` // Overrides the format of the file
+	Level  string `json:"level,omitempty"`  // Overrides the level of the file for this sink
+}
+
+// Loader builds a logger from a config file and applies level changes to it when the
+// file is reloaded. Levels are changed in place, so loggers already created with With
+// or WithGroup keep their fields and pick up the new levels. Other changes, such as
+// the format or sinks, only take effect on restart.
+type Loader struct {
+	path    string
+	log     logger.Logger
+	sinks   []logger.Logger // One per sink, in the order of cfg.Sinks
+	closers []io.Closer     // Files opened for sinks
+	cfg     File
+	modTime time.Time
+	mu      sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Load reads the config file at path and builds the logger
+func Load(path string) (*Loader, error) {
+	cfg, modTime, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ld := &Loader{
+		path:    path,
+		cfg:     cfg,
+		modTime: modTime,
+	}
+	for _, sink := range cfg.Sinks {
+		l, err := ld.newSink(cfg, sink)
+		if err != nil {
+			ld.closeFiles()
+			return nil, err
+		}
+		ld.sinks = append(ld.sinks, l)
+	}
+
+	if len(ld.sinks) == 1 {
+		ld.log = ld.sinks[0]
+	} else {
+		ld.log = logger.Multi(ld.sinks...)
+	}
+	return ld, nil
+}
+
+// Logger returns the logger built from the config file
+func (ld *Loader) Logger() logger.Logger {
+	return ld.log
+}
+
+// Reload reads the config file again and applies any level changes. If the file is
+// invalid the current levels are kept and the error is logged and returned.
+func (ld *Loader) Reload() error {
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+
+	cfg, modTime, err := readFile(ld.path)
+	if err != nil {
+		ld.log.WithError(err).Error("log config reload failed", "path", ld.path)
+		return err
+	}
+	ld.modTime = modTime
+
+	if needsRestart(ld.cfg, cfg) {
+		ld.log.Warn("log config changes other than levels need a restart", "path", ld.path)
+	}
+
+	// Sinks can't be added or removed, so levels are matched to the running sinks by position
+	sinks := make([]Sink, len(ld.sinks))
+	for i, l := range ld.sinks {
+		sinks[i] = ld.cfg.Sinks[i]
+		if i < len(cfg.Sinks) {
+			sinks[i].Level = cfg.Sinks[i].Level
+		}
+		if err := applyLevels(l, sinkLevel(cfg, sinks[i]), ld.cfg.LevelOverrides, cfg.LevelOverrides); err != nil {
+			ld.log.WithError(err).Error("log config reload failed", "path", ld.path)
+			return err
+		}
+	}
+	cfg.Sinks = sinks
+	ld.cfg = cfg
+
+	ld.log.Info("log config reloaded", "path", ld.path, "log_level", cfg.Level)
+	return nil
+}
+
+// Watch reloads the config file when the process receives SIGHUP or when the file's
+// modification time changes, checking every interval (defaults to 5 seconds). Call
+// Close to stop watching.
+func (ld *Loader) Watch(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	ld.mu.Lock()
+	if ld.stop != nil {
+		ld.mu.Unlock()
+		return
+	}
+	ld.stop = make(chan struct{})
+	ld.done = make(chan struct{})
+	ld.mu.Unlock()
+
+	go ld.watch(interval)
+}
+
+// Close stops watching and closes any files opened for sinks
+func (ld *Loader) Close() error {
+	ld.mu.Lock()
+	stop, done := ld.stop, ld.done
+	if stop != nil {
+		select {
+		case <-stop:
+		default:
+			close(stop)
+		}
+	}
+	ld.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+	return ld.closeFiles()
+}
+
+func (ld *Loader) watch(interval time.Duration) {
+	defer close(ld.done)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-hup:
+			ld.Reload()
+		case <-ticker.C:
+			if ld.changed() {
+				ld.Reload()
+			}
+		case <-ld.stop:
+			return
+		}
+	}
+}
+
+// changed reports whether the file's modification time differs from the last check,
+// so an invalid file is only reported once rather than on every poll
+func (ld *Loader) changed() bool {
+	info, err := os.Stat(ld.path)
+	if err != nil {
+		return false
+	}
+
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+	if info.ModTime().Equal(ld.modTime) {
+		return false
+	}
+	ld.modTime = info.ModTime()
+	return true
+}
+
+// newSink creates the logger for one sink
+func (ld *Loader) newSink(cfg File, sink Sink) (logger.Logger, error) {
+	var w io.Writer
+	switch strings.ToLower(sink.Output) {
+	case "", "stdout":
+		w = os.Stdout
+	case "stderr":
+		w = os.Stderr
+	default:
+		f, err := os.OpenFile(sink.Output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening sink: %w", err)
+		}
+		ld.closers = append(ld.closers, f)
+		w = f
+	}
+
+	format := cfg.Format
+	if sink.Format != "" {
+		format = sink.Format
+	}
+
+	return logger.NewFromConfig(logger.Config{
+		Backend:        cfg.Backend,
+		Level:          sinkLevel(cfg, sink),
+		Format:         format,
+		Writer:         w,
+		GroupFieldName: cfg.GroupFieldName,
+		NoColor:        cfg.NoColor,
+		LevelOverrides: cfg.LevelOverrides,
+	})
+}
+
+func (ld *Loader) closeFiles() error {
+	var firstErr error
+	for _, c := range ld.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	ld.closers = nil
+	return firstErr
+}
+
+// readFile reads, validates and fills in the defaults of a config file
+func readFile(path string) (File, time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, time.Time{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return File{}, time.Time{}, err
+	}
+
+	var cfg File
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return File{}, time.Time{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	if cfg.Backend == "" {
+		cfg.Backend = "slog"
+	}
+	if cfg.Level == "" {
+		cfg.Level = "info"
+	}
+	if len(cfg.Sinks) == 0 {
+		cfg.Sinks = []Sink{{Output: "stdout"}}
+	}
+
+	if err := validateLevel(cfg.Level); err != nil {
+		return File{}, time.Time{}, fmt.Errorf("%s: %w", path, err)
+	}
+	for group, level := range cfg.LevelOverrides {
+		if err := validateLevel(level); err != nil {
+			return File{}, time.Time{}, fmt.Errorf("%s: level override for group %q: %w", path, group, err)
+		}
+	}
+	for i, sink := range cfg.Sinks {
+		if sink.Level != "" {
+			if err := validateLevel(sink.Level); err != nil {
+				return File{}, time.Time{}, fmt.Errorf("%s: sink %d: %w", path, i, err)
+			}
+		}
+	}
+
+	return cfg, info.ModTime(), nil
+}
+
+func validateLevel(level string) error {
+	_, err := logger.ParseLevel(level)
+	return err
+}
+
+// sinkLevel returns the level for a sink, falling back to the level of the file
+func sinkLevel(cfg File, sink Sink) string {
+	if sink.Level != "" {
+		return sink.Level
+	}
+	return cfg.Level
+}
+
+// applyLevels sets the level and group overrides of l, clearing overrides no longer present
+func applyLevels(l logger.Logger, level string, oldGroups, newGroups map[string]string) error {
+	if ls, ok := l.(logger.LevelSetter); ok {
+		if err := ls.SetLevel(level); err != nil {
+			return err
+		}
+	}
+
+	gs, ok := l.(logger.GroupLevelSetter)
+	if !ok {
+		return nil
+	}
+	for group := range oldGroups {
+		if _, ok := newGroups[group]; !ok {
+			gs.ClearGroupLevel(group)
+		}
+	}
+	for group, groupLevel := range newGroups {
+		if err := gs.SetGroupLevel(group, groupLevel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// needsRestart reports whether anything other than the levels differs between two configs
+func needsRestart(old, cfg File) bool {
+	if old.Backend != cfg.Backend || old.Format != cfg.Format ||
+		old.GroupFieldName != cfg.GroupFieldName || old.NoColor != cfg.NoColor {
+		return true
+	}
+	return !slices.EqualFunc(old.Sinks, cfg.Sinks, func(a, b Sink) bool {
+		return a.Output == b.Output && a.Format == b.Format
+	})
+}
@@ -0,0 +1,28 @@
+package logger
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the given logger
+func NewContext(ctx context.Context, l Logger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by NewContext, or the default logger if there is none
+func FromContext(ctx context.Context) Logger {
+	return FromContextOr(ctx, Default())
+}
+
+// FromContextOr returns the logger stored in ctx by NewContext, or def if there is none
+func FromContextOr(ctx context.Context, def Logger) Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(Logger); ok && l != nil {
+			return l
+		}
+	}
+	return def
+}
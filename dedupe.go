@@ -0,0 +1,303 @@
+package logger
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DedupeLogger wraps a Logger and collapses identical entries, those with the same
+// level, group, message and keys, written within a window. The first entry is written
+// as normal and, once the window closes, a single follow-up entry reports how many
+// times it was repeated. Child loggers share the state of the logger they came from.
+type DedupeLogger struct {
+	logger Logger
+	group  string
+	state  *dedupeState
+}
+
+type dedupeState struct {
+	window  time.Duration
+	mu      sync.Mutex
+	entries map[string]*dedupeEntry
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+type dedupeEntry struct {
+	logger        Logger // Logger the first entry was written to, used for the summary
+	ctx           context.Context
+	level         Level
+	msg           string
+	keysAndValues []any
+	first         time.Time
+	last          time.Time
+	repeated      int
+	expires       time.Time
+}
+
+// NewDedupeLogger creates a new DedupeLogger around l collapsing repeats within window,
+// which defaults to 10 seconds. Call Close to stop it and write any pending summaries.
+func NewDedupeLogger(l Logger, window time.Duration) *DedupeLogger {
+	if window <= 0 {
+		window = 10 * time.Second
+	}
+
+	state := &dedupeState{
+		window:  window,
+		entries: make(map[string]*dedupeEntry),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go state.flushLoop()
+
+	return &DedupeLogger{
+		logger: l,
+		state:  state,
+	}
+}
+
+// Close stops the DedupeLogger, writing summaries for entries repeated in the current window
+func (d *DedupeLogger) Close() error {
+	d.state.mu.Lock()
+	select {
+	case <-d.state.stop:
+	default:
+		close(d.state.stop)
+	}
+	d.state.mu.Unlock()
+
+	<-d.state.done
+	return nil
+}
+
+func (st *dedupeState) flushLoop() {
+	defer close(st.done)
+
+	// Check often enough that summaries are at most a fraction of a window late
+	ticker := time.NewTicker(max(st.window/4, 10*time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			st.flush(now, false)
+		case <-st.stop:
+			st.flush(time.Now(), true)
+			return
+		}
+	}
+}
+
+// flush writes summaries for expired entries, or all entries if all is set
+func (st *dedupeState) flush(now time.Time, all bool) {
+	var expired []*dedupeEntry
+
+	st.mu.Lock()
+	for key, e := range st.entries {
+		if all || !now.Before(e.expires) {
+			delete(st.entries, key)
+			if e.repeated > 0 {
+				expired = append(expired, e)
+			}
+		}
+	}
+	st.mu.Unlock()
+
+	for _, e := range expired {
+		e.summarise()
+	}
+}
+
+// summarise writes the follow-up entry reporting how often the entry was repeated
+func (e *dedupeEntry) summarise() {
+	keysAndValues := make([]any, 0, len(e.keysAndValues)+6)
+	keysAndValues = append(keysAndValues, e.keysAndValues...)
+	keysAndValues = append(keysAndValues,
+		"repeated", e.repeated,
+		"first_seen", e.first,
+		"last_seen", e.last,
+	)
+	LogAt(e.logger, e.ctx, e.level, e.msg, keysAndValues...)
+}
+
+// seen records an entry, returning true if it is a repeat that should be suppressed.
+// If it replaces an entry whose window has closed but not yet been flushed, that entry
+// is returned so its summary can be written first.
+func (d *DedupeLogger) seen(ctx context.Context, level Level, msg string, keysAndValues []any) (bool, *dedupeEntry) {
+	key := dedupeKey(level, d.group, msg, keysAndValues)
+	now := time.Now()
+
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+
+	var expired *dedupeEntry
+	if e, ok := d.state.entries[key]; ok {
+		if now.Before(e.expires) {
+			e.repeated++
+			e.last = now
+			return true, nil
+		}
+		if e.repeated > 0 {
+			expired = e
+		}
+	}
+
+	// The caller may reuse its slice once we return
+	d.state.entries[key] = &dedupeEntry{
+		logger:        d.logger,
+		ctx:           ctx,
+		level:         level,
+		msg:           msg,
+		keysAndValues: append([]any(nil), keysAndValues...),
+		first:         now,
+		last:          now,
+		expires:       now.Add(d.state.window),
+	}
+	return false, expired
+}
+
+// dedupeKey identifies entries with the same level, group, message and set of keys
+func dedupeKey(level Level, group, msg string, keysAndValues []any) string {
+	keys := make([]string, 0, len(keysAndValues)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if key, ok := keysAndValues[i].(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(level.String())
+	b.WriteByte(0)
+	b.WriteString(group)
+	b.WriteByte(0)
+	b.WriteString(msg)
+	for _, key := range keys {
+		b.WriteByte(0)
+		b.WriteString(key)
+	}
+	return b.String()
+}
+
+func (d *DedupeLogger) Trace(msg string, keysAndValues ...any) {
+	d.Log(nil, LevelTrace, msg, keysAndValues...)
+}
+
+func (d *DedupeLogger) Debug(msg string, keysAndValues ...any) {
+	d.Log(nil, LevelDebug, msg, keysAndValues...)
+}
+
+func (d *DedupeLogger) Info(msg string, keysAndValues ...any) {
+	d.Log(nil, LevelInfo, msg, keysAndValues...)
+}
+
+func (d *DedupeLogger) Warn(msg string, keysAndValues ...any) {
+	d.Log(nil, LevelWarn, msg, keysAndValues...)
+}
+
+func (d *DedupeLogger) Error(msg string, keysAndValues ...any) {
+	d.Log(nil, LevelError, msg, keysAndValues...)
+}
+
+func (d *DedupeLogger) Fatal(msg string, keysAndValues ...any) {
+	d.Flush(context.Background())
+	d.logger.Fatal(msg, keysAndValues...)
+}
+
+func (d *DedupeLogger) TraceContext(ctx context.Context, msg string, keysAndValues ...any) {
+	d.Log(ctx, LevelTrace, msg, keysAndValues...)
+}
+
+func (d *DedupeLogger) DebugContext(ctx context.Context, msg string, keysAndValues ...any) {
+	d.Log(ctx, LevelDebug, msg, keysAndValues...)
+}
+
+func (d *DedupeLogger) InfoContext(ctx context.Context, msg string, keysAndValues ...any) {
+	d.Log(ctx, LevelInfo, msg, keysAndValues...)
+}
+
+func (d *DedupeLogger) WarnContext(ctx context.Context, msg string, keysAndValues ...any) {
+	d.Log(ctx, LevelWarn, msg, keysAndValues...)
+}
+
+func (d *DedupeLogger) ErrorContext(ctx context.Context, msg string, keysAndValues ...any) {
+	d.Log(ctx, LevelError, msg, keysAndValues...)
+}
+
+func (d *DedupeLogger) FatalContext(ctx context.Context, msg string, keysAndValues ...any) {
+	d.Flush(context.Background())
+	if cl, ok := d.logger.(ContextLogger); ok {
+		cl.FatalContext(ctx, msg, keysAndValues...)
+		return
+	}
+	d.logger.Fatal(msg, keysAndValues...)
+}
+
+// Log writes an entry at the given level unless it repeats one already written in the
+// current window, without exiting for LevelFatal
+func (d *DedupeLogger) Log(ctx context.Context, level Level, msg string, keysAndValues ...any) {
+	if level >= LevelFatal || !IsEnabled(d.logger, level) {
+		LogAt(d.logger, ctx, level, msg, keysAndValues...)
+		return
+	}
+
+	repeat, expired := d.seen(ctx, level, msg, keysAndValues)
+	if expired != nil {
+		expired.summarise()
+	}
+	if !repeat {
+		LogAt(d.logger, ctx, level, msg, keysAndValues...)
+	}
+}
+
+// Flush writes summaries for all entries repeated in the current window, then flushes
+// the wrapped logger if it implements Flusher
+func (d *DedupeLogger) Flush(ctx context.Context) error {
+	d.state.flush(time.Now(), true)
+	return flush(ctx, d.logger)
+}
+
+// Enabled reports whether the wrapped logger would write an entry at the given level
+func (d *DedupeLogger) Enabled(level Level) bool {
+	return IsEnabled(d.logger, level)
+}
+
+// SetLevel sets the level of the wrapped logger if it implements LevelSetter
+func (d *DedupeLogger) SetLevel(level string) error {
+	return setLevel(d.logger, level)
+}
+
+// Level returns the level of the wrapped logger
+func (d *DedupeLogger) Level() string {
+	return levelOf(d.logger)
+}
+
+// SetGroupLevel sets the level for the group on the wrapped logger if it implements GroupLevelSetter
+func (d *DedupeLogger) SetGroupLevel(group, level string) error {
+	return setGroupLevel(d.logger, group, level)
+}
+
+// ClearGroupLevel removes the override for the group from the wrapped logger
+func (d *DedupeLogger) ClearGroupLevel(group string) {
+	clearGroupLevel(d.logger, group)
+}
+
+// GroupLevels returns the group overrides of the wrapped logger
+func (d *DedupeLogger) GroupLevels() map[string]string {
+	return groupLevelsOf(d.logger)
+}
+
+func (d *DedupeLogger) With(key string, value any) Logger {
+	return &DedupeLogger{logger: d.logger.With(key, value), group: d.group, state: d.state}
+}
+
+func (d *DedupeLogger) WithError(err error) Logger {
+	return &DedupeLogger{logger: d.logger.WithError(err), group: d.group, state: d.state}
+}
+
+func (d *DedupeLogger) WithGroup(group string) Logger {
+	return &DedupeLogger{logger: d.logger.WithGroup(group), group: group, state: d.state}
+}
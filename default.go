@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// defaultHolder wraps the default logger so it can be stored atomically whatever its type
+type defaultHolder struct {
+	logger Logger
+}
+
+var defaultLogger atomic.Pointer[defaultHolder]
+
+// SetDefault replaces the logger used by the package-level functions and by FromContext
+// when a context carries no logger. Passing nil restores the NullLogger.
+func SetDefault(l Logger) {
+	if l == nil {
+		l = NullLogger{}
+	}
+	defaultLogger.Store(&defaultHolder{logger: l})
+}
+
+// Default returns the logger set with SetDefault, a NullLogger until one is set
+func Default() Logger {
+	if h := defaultLogger.Load(); h != nil {
+		return h.logger
+	}
+	return NullLogger{}
+}
+
+// Trace logs to the default logger
+func Trace(msg string, keysAndValues ...any) {
+	Default().Trace(msg, keysAndValues...)
+}
+
+// Debug logs to the default logger
+func Debug(msg string, keysAndValues ...any) {
+	Default().Debug(msg, keysAndValues...)
+}
+
+// Info logs to the default logger
+func Info(msg string, keysAndValues ...any) {
+	Default().Info(msg, keysAndValues...)
+}
+
+// Warn logs to the default logger
+func Warn(msg string, keysAndValues ...any) {
+	Default().Warn(msg, keysAndValues...)
+}
+
+// Error logs to the default logger
+func Error(msg string, keysAndValues ...any) {
+	Default().Error(msg, keysAndValues...)
+}
+
+// Fatal logs to the default logger, which exits through Exit. Like log.Fatal it ends
+// the process even before SetDefault is called, when the NullLogger writes nothing.
+func Fatal(msg string, keysAndValues ...any) {
+	l := Default()
+	l.Fatal(msg, keysAndValues...)
+	if _, ok := l.(NullLogger); ok {
+		Exit(ExitCode(keysAndValues))
+	}
+}
+
+// With returns a child of the default logger with the key-value pair added
+func With(key string, value any) Logger {
+	return Default().With(key, value)
+}
+
+// WithError returns a child of the default logger with the error added
+func WithError(err error) Logger {
+	return Default().WithError(err)
+}
+
+// WithGroup returns a child of the default logger for the group
+func WithGroup(group string) Logger {
+	return Default().WithGroup(group)
+}
+
+// Flush waits for the default logger to write any buffered entries, for use on shutdown
+func Flush(ctx context.Context) error {
+	return flush(ctx, Default())
+}
+
+// flush flushes l if it implements Flusher
+func flush(ctx context.Context, l Logger) error {
+	if f, ok := l.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
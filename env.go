@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ConfigFromEnv builds a Config from environment variables whose names start with
+// prefix, e.g. "MYAPP_" reads MYAPP_LOG_LEVEL:
+//
+//	<prefix>LOG_BACKEND          Backend name for NewFromConfig
+//	<prefix>LOG_LEVEL            "trace", "debug", "info", "warn" or "error"
+//	<prefix>LOG_FORMAT           "console" or "json"
+//	<prefix>LOG_OUTPUT           "stdout", "stderr" or the path of a file to append to
+//	<prefix>LOG_GROUP_FIELD      Field name for groups
+//	<prefix>LOG_LEVEL_OVERRIDES  Per-group levels, e.g. "database=trace,http=warn"
+//	NO_COLOR                     Disables colored console output when set, see https://no-color.org
+//
+// Unset variables are left empty so the backend defaults apply. When LOG_OUTPUT names a
+// file, Writer is the opened *os.File and closing it is left to the caller.
+func ConfigFromEnv(prefix string) (Config, error) {
+	var cfg Config
+
+	env := func(name string) (string, string) {
+		name = prefix + name
+		return name, strings.TrimSpace(os.Getenv(name))
+	}
+
+	if _, value := env("LOG_BACKEND"); value != "" {
+		cfg.Backend = strings.ToLower(value)
+	}
+
+	if name, value := env("LOG_LEVEL"); value != "" {
+		level, err := ParseLevel(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %w", name, err)
+		}
+		cfg.Level = level.String()
+	}
+
+	if name, value := env("LOG_FORMAT"); value != "" {
+		switch format := strings.ToLower(value); format {
+		case "console", "json":
+			cfg.Format = format
+		default:
+			return Config{}, fmt.Errorf("%s: unknown log format %q, expected console or json", name, value)
+		}
+	}
+
+	if name, value := env("LOG_LEVEL_OVERRIDES"); value != "" {
+		overrides, err := ParseLevelOverrides(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %w", name, err)
+		}
+		for group, level := range overrides {
+			lvl, err := ParseLevel(level)
+			if err != nil {
+				return Config{}, fmt.Errorf("%s: group %q: %w", name, group, err)
+			}
+			overrides[group] = lvl.String()
+		}
+		cfg.LevelOverrides = overrides
+	}
+
+	if _, value := env("LOG_GROUP_FIELD"); value != "" {
+		cfg.GroupFieldName = value
+	}
+
+	cfg.NoColor = os.Getenv("NO_COLOR") != ""
+
+	// Opened last so no file is left open when validation fails
+	if name, value := env("LOG_OUTPUT"); value != "" {
+		switch strings.ToLower(value) {
+		case "stdout":
+			cfg.Writer = os.Stdout
+		case "stderr":
+			cfg.Writer = os.Stderr
+		default:
+			f, err := os.OpenFile(value, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+			if err != nil {
+				return Config{}, fmt.Errorf("%s: %w", name, err)
+			}
+			cfg.Writer = f
+		}
+	}
+
+	return cfg, nil
+}
@@ -1,7 +1,7 @@
 package main
 
-// This is an example of how to create an application-level log package
-// Copy this pattern to your application as internal/log/log.go
+// This is an example of configuring the default logger and using the
+// package-level logging functions from the core package
 
 import (
 	"flag"
@@ -12,54 +12,13 @@ import (
 	logslog "github.com/paularlott/logger/slog"
 )
 
-// Example: Application-level log package
-var defaultLogger logger.Logger
-
-func init() {
-	// Initialize with default configuration
-	defaultLogger = logslog.New(logslog.Config{
-		Level:  "info",
-		Format: "console",
-		Writer: os.Stdout,
-	})
-}
-
-// Configure sets up the logger
+// Configure sets up the default logger
 func Configure(level, format string) {
-	defaultLogger = logslog.New(logslog.Config{
+	logger.SetDefault(logslog.New(logslog.Config{
 		Level:  level,
 		Format: format,
 		Writer: os.Stdout,
-	})
-}
-
-// Package-level functions for convenience
-func Info(msg string, keysAndValues ...any) {
-	defaultLogger.Info(msg, keysAndValues...)
-}
-
-func Debug(msg string, keysAndValues ...any) {
-	defaultLogger.Debug(msg, keysAndValues...)
-}
-
-func Warn(msg string, keysAndValues ...any) {
-	defaultLogger.Warn(msg, keysAndValues...)
-}
-
-func Error(msg string, keysAndValues ...any) {
-	defaultLogger.Error(msg, keysAndValues...)
-}
-
-func With(key string, value any) logger.Logger {
-	return defaultLogger.With(key, value)
-}
-
-func WithError(err error) logger.Logger {
-	return defaultLogger.WithError(err)
-}
-
-func GetLogger() logger.Logger {
-	return defaultLogger
+	}))
 }
 
 // Example: A service that accepts the logger interface
@@ -99,34 +58,34 @@ func main() {
 	// Configure logging
 	Configure(*level, *format)
 
-	Info("application starting", "version", "1.0.0")
+	logger.Info("application starting", "version", "1.0.0")
 
 	// Create service with logger
-	svc := NewUserService(GetLogger())
+	svc := NewUserService(logger.Default())
 
 	// Demonstrate various logging features
-	Info("demonstrating logging features")
+	logger.Info("demonstrating logging features")
 
 	// Simple logging
-	defaultLogger.Trace("debug message", "detail", "some detail")
-	Debug("debug message", "detail", "some detail")
-	Info("info message", "count", 42)
-	Warn("warning message", "deprecated", "oldFeature")
+	logger.Trace("debug message", "detail", "some detail")
+	logger.Debug("debug message", "detail", "some detail")
+	logger.Info("info message", "count", 42)
+	logger.Warn("warning message", "deprecated", "oldFeature")
 
 	// With contextual fields
-	reqLog := With("request_id", "abc123").With("ip", "192.168.1.1")
+	reqLog := logger.With("request_id", "abc123").With("ip", "192.168.1.1")
 	reqLog.Info("processing request")
 	reqLog.Debug("validated input")
 
 	// Service logging
 	if err := svc.Login(123, "john"); err != nil {
-		Error("login error", "error", err)
+		logger.Error("login error", "error", err)
 	}
 
 	// Error logging
 	if err := svc.Login(0, "invalid"); err != nil {
-		WithError(err).Error("operation failed")
+		logger.WithError(err).Error("operation failed")
 	}
 
-	Info("application stopped")
+	logger.Info("application stopped")
 }
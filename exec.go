@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// RunCommand starts cmd and waits for it to finish, writing each line of its stdout
+// and stderr to l at the given levels. Entries are written to a child logger tagged
+// with the command name and pid, along with entries for the start, exit code and
+// duration. Output streams already set on cmd are left alone. It returns the error
+// from starting or waiting for the command.
+func RunCommand(l Logger, cmd *exec.Cmd, stdoutLevel, stderrLevel Level) error {
+	cmdLog := l.With("cmd", filepath.Base(cmd.Path))
+
+	// Writers rather than pipes so exec copies the output and cmd.WaitDelay applies when
+	// a grandchild holds the streams open
+	var writers []*LineWriter
+	if cmd.Stdout == nil {
+		w := Writer(cmdLog, stdoutLevel)
+		cmd.Stdout = w
+		writers = append(writers, w)
+	}
+	if cmd.Stderr == nil {
+		w := Writer(cmdLog, stderrLevel)
+		cmd.Stderr = w
+		writers = append(writers, w)
+	}
+
+	// Hold the writers until the pid is known, so every line is tagged with it and
+	// comes after the start entry
+	for _, w := range writers {
+		w.mu.Lock()
+	}
+	start := time.Now()
+	err := cmd.Start()
+	if err == nil {
+		cmdLog = cmdLog.With("pid", cmd.Process.Pid)
+		cmdLog.Info("command started", "args", cmd.Args)
+	}
+	for _, w := range writers {
+		w.logger = cmdLog
+		w.mu.Unlock()
+	}
+
+	if err != nil {
+		cmdLog.WithError(err).Error("command failed to start", "args", cmd.Args)
+		return err
+	}
+
+	err = cmd.Wait()
+	for _, w := range writers {
+		w.Close()
+	}
+	duration := time.Since(start)
+
+	exitCode := -1
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+
+	if err != nil {
+		cmdLog.WithError(err).Error("command failed", "exit_code", exitCode, "duration", duration)
+	} else {
+		cmdLog.Info("command finished", "exit_code", exitCode, "duration", duration)
+	}
+	return err
+}
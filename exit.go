@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ExitCodeKey sets the status a Fatal call exits with, e.g.
+// log.Fatal("invalid config", logger.ExitCodeKey, 78). Without it the status is 1.
+const ExitCodeKey = "exit_code"
+
+const exitFlushTimeout = 5 * time.Second
+
+var (
+	exitMu    sync.Mutex
+	exitFunc  = os.Exit
+	exitHooks []func()
+	exiting   atomic.Bool
+)
+
+// SetExitFunc replaces os.Exit as the function Fatal calls once the entry is written,
+// e.g. to panic or record the code in tests. Passing nil restores os.Exit.
+func SetExitFunc(fn func(code int)) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	if fn == nil {
+		fn = os.Exit
+	}
+	exitFunc = fn
+}
+
+// OnExit registers a function to run before Fatal exits, e.g. to close files or notify
+// a supervisor. Hooks run in the reverse order they were registered, like deferred calls.
+func OnExit(fn func()) {
+	if fn == nil {
+		return
+	}
+
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	exitHooks = append(exitHooks, fn)
+}
+
+// Exit flushes the default logger, runs the hooks registered with OnExit and calls the
+// exit function with code. Backends call it from Fatal; a hook that itself calls Fatal
+// exits straight away rather than running the hooks again.
+func Exit(code int) {
+	exitMu.Lock()
+	fn := exitFunc
+	hooks := append([]func(){}, exitHooks...)
+	exitMu.Unlock()
+
+	if exiting.CompareAndSwap(false, true) {
+		// Reset in case the exit function returns, as it may in tests
+		defer exiting.Store(false)
+
+		ctx, cancel := context.WithTimeout(context.Background(), exitFlushTimeout)
+		flush(ctx, Default())
+		cancel()
+
+		for i := len(hooks) - 1; i >= 0; i-- {
+			runExitHook(hooks[i])
+		}
+	}
+
+	fn(code)
+}
+
+// runExitHook calls a hook, reporting rather than propagating a panic so the
+// remaining hooks still run and the process still exits
+func runExitHook(hook func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "logger: exit hook panicked: %v\n", r)
+		}
+	}()
+	hook()
+}
+
+// ExitCode returns the status set with ExitCodeKey in keysAndValues, 1 if there is none
+func ExitCode(keysAndValues []any) int {
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if key, ok := keysAndValues[i].(string); !ok || key != ExitCodeKey {
+			continue
+		}
+
+		switch code := keysAndValues[i+1].(type) {
+		case int:
+			return code
+		case int8:
+			return int(code)
+		case int16:
+			return int(code)
+		case int32:
+			return int(code)
+		case int64:
+			return int(code)
+		case uint:
+			return int(code)
+		case uint8:
+			return int(code)
+		case uint16:
+			return int(code)
+		case uint32:
+			return int(code)
+		case uint64:
+			return int(code)
+		}
+	}
+	return 1
+}
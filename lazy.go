@@ -0,0 +1,20 @@
+package logger
+
+// LazyValue is a log value that is only computed if the entry using it is written.
+// Create one with Lazy and pass it as a value in keysAndValues or to With.
+type LazyValue struct {
+	fn func() any
+}
+
+// Lazy wraps fn so that it is only called when an entry using the value is written
+func Lazy(fn func() any) LazyValue {
+	return LazyValue{fn: fn}
+}
+
+// Value calls the wrapped function and returns its result
+func (v LazyValue) Value() any {
+	if v.fn == nil {
+		return nil
+	}
+	return v.fn()
+}
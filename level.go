@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Level is a log level, ordered from most to least verbose
+type Level int
+
+const (
+	LevelTrace Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+// String returns the lower case name of the level, e.g. "info"
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return "trace"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelFatal:
+		return "fatal"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+// ParseLevel converts a level name such as "debug" to a Level
+func ParseLevel(level string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "trace":
+		return LevelTrace, nil
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	case "fatal":
+		return LevelFatal, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+// IsEnabled reports whether l would write an entry at the given level. Loggers that
+// don't implement LevelEnabler are assumed to write every level.
+func IsEnabled(l Logger, level Level) bool {
+	if le, ok := l.(LevelEnabler); ok {
+		return le.Enabled(level)
+	}
+	return true
+}
+
+// setLevel sets the level of l, for wrappers forwarding LevelSetter
+func setLevel(l Logger, level string) error {
+	ls, ok := l.(LevelSetter)
+	if !ok {
+		return fmt.Errorf("logger does not support runtime level changes")
+	}
+	return ls.SetLevel(level)
+}
+
+// levelOf returns the level of l, "" if it doesn't implement LevelSetter
+func levelOf(l Logger) string {
+	if ls, ok := l.(LevelSetter); ok {
+		return ls.Level()
+	}
+	return ""
+}
+
+// setGroupLevel sets the level for the group on l, for wrappers forwarding GroupLevelSetter
+func setGroupLevel(l Logger, group, level string) error {
+	gs, ok := l.(GroupLevelSetter)
+	if !ok {
+		return fmt.Errorf("logger does not support group levels")
+	}
+	return gs.SetGroupLevel(group, level)
+}
+
+// clearGroupLevel removes the override for the group from l if it implements GroupLevelSetter
+func clearGroupLevel(l Logger, group string) {
+	if gs, ok := l.(GroupLevelSetter); ok {
+		gs.ClearGroupLevel(group)
+	}
+}
+
+// groupLevelsOf returns the group overrides of l, empty if it doesn't implement GroupLevelSetter
+func groupLevelsOf(l Logger) map[string]string {
+	if gs, ok := l.(GroupLevelSetter); ok {
+		return gs.GroupLevels()
+	}
+	return map[string]string{}
+}
+
+// LogAt writes an entry to l at a level chosen at runtime, without exiting for LevelFatal.
+// Loggers that don't implement LevelLogger receive fatal entries at error level.
+func LogAt(l Logger, ctx context.Context, level Level, msg string, keysAndValues ...any) {
+	if ll, ok := l.(LevelLogger); ok {
+		ll.Log(ctx, level, msg, keysAndValues...)
+		return
+	}
+
+	if cl, ok := l.(ContextLogger); ok && ctx != nil {
+		switch level {
+		case LevelTrace:
+			cl.TraceContext(ctx, msg, keysAndValues...)
+		case LevelDebug:
+			cl.DebugContext(ctx, msg, keysAndValues...)
+		case LevelInfo:
+			cl.InfoContext(ctx, msg, keysAndValues...)
+		case LevelWarn:
+			cl.WarnContext(ctx, msg, keysAndValues...)
+		default:
+			cl.ErrorContext(ctx, msg, keysAndValues...)
+		}
+		return
+	}
+
+	switch level {
+	case LevelTrace:
+		l.Trace(msg, keysAndValues...)
+	case LevelDebug:
+		l.Debug(msg, keysAndValues...)
+	case LevelInfo:
+		l.Info(msg, keysAndValues...)
+	case LevelWarn:
+		l.Warn(msg, keysAndValues...)
+	default:
+		l.Error(msg, keysAndValues...)
+	}
+}
+
+// ParseLevelOverrides parses per-group level overrides in the form
+// "database=trace,http=warn", as used in environment variables and flags
+func ParseLevelOverrides(s string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		group, level, ok := strings.Cut(part, "=")
+		group = strings.TrimSpace(group)
+		level = strings.TrimSpace(level)
+		if !ok || group == "" || level == "" {
+			return nil, fmt.Errorf("invalid level override %q, expected group=level", part)
+		}
+		overrides[group] = strings.ToLower(level)
+	}
+	return overrides, nil
+}
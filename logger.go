@@ -1,5 +1,7 @@
 package logger
 
+import "context"
+
 // Logger is the minimal interface all paularlott/* libraries accept
 type Logger interface {
 	Trace(msg string, keysAndValues ...any)
@@ -7,8 +9,58 @@ type Logger interface {
 	Info(msg string, keysAndValues ...any)
 	Warn(msg string, keysAndValues ...any)
 	Error(msg string, keysAndValues ...any)
-	Fatal(msg string, keysAndValues ...any) // Logs and exits with status 1
+	Fatal(msg string, keysAndValues ...any) // Logs and exits, with status 1 unless ExitCodeKey is given
 	With(key string, value any) Logger
 	WithError(err error) Logger
 	WithGroup(group string) Logger
 }
+
+// ContextLogger is an optional extension to Logger for implementations that can
+// pass a context.Context through to the underlying handler, e.g. for trace IDs.
+// Callers should detect it with a type assertion and fall back to the plain methods.
+type ContextLogger interface {
+	Logger
+	TraceContext(ctx context.Context, msg string, keysAndValues ...any)
+	DebugContext(ctx context.Context, msg string, keysAndValues ...any)
+	InfoContext(ctx context.Context, msg string, keysAndValues ...any)
+	WarnContext(ctx context.Context, msg string, keysAndValues ...any)
+	ErrorContext(ctx context.Context, msg string, keysAndValues ...any)
+	FatalContext(ctx context.Context, msg string, keysAndValues ...any) // Logs and exits, with status 1 unless ExitCodeKey is given
+}
+
+// LevelSetter is an optional extension implemented by loggers whose minimum level
+// can be changed at runtime. The level is shared by a root logger and every child
+// created from it with With, WithError or WithGroup.
+type LevelSetter interface {
+	SetLevel(level string) error // "trace", "debug", "info", "warn", "error"
+	Level() string
+}
+
+// GroupLevelSetter is an optional extension implemented by loggers that can override
+// the minimum level for child loggers created with WithGroup. Overrides are shared
+// by a root logger and every child created from it.
+type GroupLevelSetter interface {
+	SetGroupLevel(group, level string) error
+	ClearGroupLevel(group string)
+	GroupLevels() map[string]string
+}
+
+// LevelEnabler is an optional extension implemented by loggers that can report whether
+// an entry at the given level would be written, so callers can skip building expensive
+// arguments. Use IsEnabled to check any Logger.
+type LevelEnabler interface {
+	Enabled(level Level) bool
+}
+
+// LevelLogger is an optional extension implemented by loggers that can write an entry at
+// a level chosen at runtime. Unlike Fatal, writing at LevelFatal with Log does not exit,
+// which lets wrappers such as Multi write to several loggers before exiting once.
+type LevelLogger interface {
+	Log(ctx context.Context, level Level, msg string, keysAndValues ...any)
+}
+
+// Flusher is an optional extension implemented by loggers that buffer entries, such as
+// AsyncLogger, so they can be written out before the application exits
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
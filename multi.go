@@ -0,0 +1,213 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// MultiLogger writes every entry to each of a set of loggers, e.g. colored console
+// output to stdout and JSON to a file, each filtering at its own level
+type MultiLogger struct {
+	loggers []Logger
+}
+
+// Multi creates a logger that writes to all of the given loggers. Fatal writes the
+// entry to every logger before exiting once; loggers that don't implement LevelLogger
+// receive it at error level so they don't exit before the others have written.
+func Multi(loggers ...Logger) Logger {
+	m := &MultiLogger{
+		loggers: make([]Logger, 0, len(loggers)),
+	}
+	for _, l := range loggers {
+		if l != nil {
+			m.loggers = append(m.loggers, l)
+		}
+	}
+	return m
+}
+
+func (m *MultiLogger) Trace(msg string, keysAndValues ...any) {
+	m.Log(nil, LevelTrace, msg, keysAndValues...)
+}
+
+func (m *MultiLogger) Debug(msg string, keysAndValues ...any) {
+	m.Log(nil, LevelDebug, msg, keysAndValues...)
+}
+
+func (m *MultiLogger) Info(msg string, keysAndValues ...any) {
+	m.Log(nil, LevelInfo, msg, keysAndValues...)
+}
+
+func (m *MultiLogger) Warn(msg string, keysAndValues ...any) {
+	m.Log(nil, LevelWarn, msg, keysAndValues...)
+}
+
+func (m *MultiLogger) Error(msg string, keysAndValues ...any) {
+	m.Log(nil, LevelError, msg, keysAndValues...)
+}
+
+func (m *MultiLogger) Fatal(msg string, keysAndValues ...any) {
+	m.Log(nil, LevelFatal, msg, keysAndValues...)
+	m.Flush(context.Background())
+	Exit(ExitCode(keysAndValues))
+}
+
+func (m *MultiLogger) TraceContext(ctx context.Context, msg string, keysAndValues ...any) {
+	m.Log(ctx, LevelTrace, msg, keysAndValues...)
+}
+
+func (m *MultiLogger) DebugContext(ctx context.Context, msg string, keysAndValues ...any) {
+	m.Log(ctx, LevelDebug, msg, keysAndValues...)
+}
+
+func (m *MultiLogger) InfoContext(ctx context.Context, msg string, keysAndValues ...any) {
+	m.Log(ctx, LevelInfo, msg, keysAndValues...)
+}
+
+func (m *MultiLogger) WarnContext(ctx context.Context, msg string, keysAndValues ...any) {
+	m.Log(ctx, LevelWarn, msg, keysAndValues...)
+}
+
+func (m *MultiLogger) ErrorContext(ctx context.Context, msg string, keysAndValues ...any) {
+	m.Log(ctx, LevelError, msg, keysAndValues...)
+}
+
+func (m *MultiLogger) FatalContext(ctx context.Context, msg string, keysAndValues ...any) {
+	m.Log(ctx, LevelFatal, msg, keysAndValues...)
+	m.Flush(context.Background())
+	Exit(ExitCode(keysAndValues))
+}
+
+// Log writes an entry at the given level to every logger, without exiting for LevelFatal
+func (m *MultiLogger) Log(ctx context.Context, level Level, msg string, keysAndValues ...any) {
+	for _, l := range m.loggers {
+		LogAt(l, ctx, level, msg, keysAndValues...)
+	}
+}
+
+// Flush flushes every logger that implements Flusher, returning the first error
+func (m *MultiLogger) Flush(ctx context.Context) error {
+	var firstErr error
+	for _, l := range m.loggers {
+		if err := flush(ctx, l); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// SetLevel sets the level of every logger that implements LevelSetter
+func (m *MultiLogger) SetLevel(level string) error {
+	var errs []error
+	supported := false
+	for _, l := range m.loggers {
+		if ls, ok := l.(LevelSetter); ok {
+			supported = true
+			errs = append(errs, ls.SetLevel(level))
+		}
+	}
+	if !supported {
+		return fmt.Errorf("logger does not support runtime level changes")
+	}
+	return errors.Join(errs...)
+}
+
+// Level returns the lowest level of the loggers that implement LevelSetter, the
+// level entries are written at by at least one of them
+func (m *MultiLogger) Level() string {
+	var lowest string
+	for _, l := range m.loggers {
+		if ls, ok := l.(LevelSetter); ok {
+			if level := ls.Level(); lowest == "" || lowerLevel(level, lowest) {
+				lowest = level
+			}
+		}
+	}
+	return lowest
+}
+
+// SetGroupLevel sets the level for the group on every logger that implements GroupLevelSetter
+func (m *MultiLogger) SetGroupLevel(group, level string) error {
+	var errs []error
+	supported := false
+	for _, l := range m.loggers {
+		if gs, ok := l.(GroupLevelSetter); ok {
+			supported = true
+			errs = append(errs, gs.SetGroupLevel(group, level))
+		}
+	}
+	if !supported {
+		return fmt.Errorf("logger does not support group levels")
+	}
+	return errors.Join(errs...)
+}
+
+// ClearGroupLevel removes the override for the group from every logger that implements GroupLevelSetter
+func (m *MultiLogger) ClearGroupLevel(group string) {
+	for _, l := range m.loggers {
+		if gs, ok := l.(GroupLevelSetter); ok {
+			gs.ClearGroupLevel(group)
+		}
+	}
+}
+
+// GroupLevels returns the group overrides of every logger that implements
+// GroupLevelSetter, the lowest level where they differ
+func (m *MultiLogger) GroupLevels() map[string]string {
+	result := make(map[string]string)
+	for _, l := range m.loggers {
+		gs, ok := l.(GroupLevelSetter)
+		if !ok {
+			continue
+		}
+		for group, level := range gs.GroupLevels() {
+			existing, ok := result[group]
+			if !ok || lowerLevel(level, existing) {
+				result[group] = level
+			}
+		}
+	}
+	return result
+}
+
+// lowerLevel reports whether level a is below level b, names that don't parse sorting last
+func lowerLevel(a, b string) bool {
+	la, errA := ParseLevel(a)
+	lb, errB := ParseLevel(b)
+	if errA != nil {
+		return false
+	}
+	return errB != nil || la < lb
+}
+
+// Enabled reports whether any of the loggers would write an entry at the given level
+func (m *MultiLogger) Enabled(level Level) bool {
+	for _, l := range m.loggers {
+		if IsEnabled(l, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MultiLogger) With(key string, value any) Logger {
+	return m.each(func(l Logger) Logger { return l.With(key, value) })
+}
+
+func (m *MultiLogger) WithError(err error) Logger {
+	return m.each(func(l Logger) Logger { return l.WithError(err) })
+}
+
+func (m *MultiLogger) WithGroup(group string) Logger {
+	return m.each(func(l Logger) Logger { return l.WithGroup(group) })
+}
+
+// each returns a new MultiLogger holding the result of fn for every logger
+func (m *MultiLogger) each(fn func(Logger) Logger) *MultiLogger {
+	loggers := make([]Logger, len(m.loggers))
+	for i, l := range m.loggers {
+		loggers[i] = fn(l)
+	}
+	return &MultiLogger{loggers: loggers}
+}
@@ -1,5 +1,7 @@
 package logger
 
+import "context"
+
 // NullLogger is a no-op logger implementation
 type NullLogger struct{}
 
@@ -16,3 +18,13 @@ func (NullLogger) Fatal(msg string, keysAndValues ...any) {} // No-op: does not
 func (n NullLogger) With(key string, value any) Logger    { return n }
 func (n NullLogger) WithError(err error) Logger           { return n }
 func (n NullLogger) WithGroup(group string) Logger        { return n }
+func (NullLogger) Enabled(level Level) bool               { return false }
+
+func (NullLogger) Log(ctx context.Context, level Level, msg string, keysAndValues ...any) {}
+
+func (NullLogger) TraceContext(ctx context.Context, msg string, keysAndValues ...any) {}
+func (NullLogger) DebugContext(ctx context.Context, msg string, keysAndValues ...any) {}
+func (NullLogger) InfoContext(ctx context.Context, msg string, keysAndValues ...any)  {}
+func (NullLogger) WarnContext(ctx context.Context, msg string, keysAndValues ...any)  {}
+func (NullLogger) ErrorContext(ctx context.Context, msg string, keysAndValues ...any) {}
+func (NullLogger) FatalContext(ctx context.Context, msg string, keysAndValues ...any) {} // No-op: does not exit
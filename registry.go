@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Config for creating a logger with NewFromConfig, independent of the backend
+type Config struct {
+	Backend        string    // Registered backend name, e.g. "slog", "zerolog" or "null"
+	Level          string    // "trace", "debug", "info", "warn", "error"
+	Format         string    // "console" or "json"
+	Writer         io.Writer // Output writer, defaults to os.Stdout
+	GroupFieldName string    // Field name for groups, defaults to "_group"
+	NoColor        bool      // Disable colors in console output
+	AddCaller      bool      // Add the file and line of the log call to each entry
+	CallerSkip     int       // Extra frames to skip when the application wraps the logger
+	StackTrace     bool      // Add a stack trace to error and fatal entries
+
+	// LevelOverrides sets the level for loggers created with WithGroup, keyed by group name,
+	// e.g. {"database": "trace", "http": "warn"}. See ParseLevelOverrides for the string form.
+	LevelOverrides map[string]string
+}
+
+// Constructor creates a logger for a backend from a Config
+type Constructor func(cfg Config) (Logger, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Constructor)
+)
+
+func init() {
+	Register("null", func(Config) (Logger, error) {
+		return NullLogger{}, nil
+	})
+}
+
+// Register makes a backend available to NewFromConfig under the given name. Backend
+// packages register themselves when imported, e.g. "slog" by github.com/paularlott/logger/slog.
+// It panics if called twice with the same name or with a nil constructor.
+func Register(name string, fn Constructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if fn == nil {
+		panic("logger: Register constructor is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("logger: Register called twice for backend " + name)
+	}
+	registry[name] = fn
+}
+
+// Backends returns the sorted names of the registered backends
+func Backends() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewFromConfig creates a logger using the backend named in cfg.Backend
+func NewFromConfig(cfg Config) (Logger, error) {
+	name := strings.ToLower(strings.TrimSpace(cfg.Backend))
+	if name == "" {
+		return nil, fmt.Errorf("logger: no backend set (registered: %s)", strings.Join(Backends(), ", "))
+	}
+
+	registryMu.RLock()
+	fn, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("logger: unknown backend %q (registered: %s), is the backend package imported?", cfg.Backend, strings.Join(Backends(), ", "))
+	}
+
+	l, err := fn(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("logger: creating %s backend: %w", name, err)
+	}
+	return l, nil
+}
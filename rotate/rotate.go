@@ -0,0 +1,390 @@
+package logrotate
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// Config for creating a new Writer
+type Config struct {
+	Filename   string        // Path of the log file, parent directories are created if needed
+	MaxSize    int64         // Rotate before the file would exceed this many bytes, 0 disables
+	Interval   string        // "hourly" or "daily" to also rotate at the start of each hour or day, "" disables
+	MaxBackups int           // Rotated files to keep, 0 keeps all
+	MaxAge     time.Duration // Remove rotated files older than this, 0 keeps all
+	Compress   bool          // Gzip rotated files
+}
+
+// Writer is an io.WriteCloser that writes to a file, moving it aside to a timestamped
+// backup such as "app-2025-10-15T12-23-00.000.log" when it grows too large or a new
+// hour or day starts. Each Write is written whole to a single file, so it is safe to
+// share between loggers and goroutines.
+type Writer struct {
+	cfg        Config
+	mu         sync.Mutex
+	file       *os.File
+	size       int64
+	nextRotate time.Time // Zero when not rotating by time
+	closed     bool
+	millCh     chan struct{} // Wakes the mill goroutine after a rotation
+	millDone   chan struct{} // Closed when the mill goroutine exits
+}
+
+// New opens the log file, appending to it if it exists
+func New(cfg Config) (*Writer, error) {
+	if cfg.Filename == "" {
+		return nil, fmt.Errorf("logrotate: filename is required")
+	}
+	switch strings.ToLower(cfg.Interval) {
+	case "", "hourly", "daily":
+		cfg.Interval = strings.ToLower(cfg.Interval)
+	default:
+		return nil, fmt.Errorf("logrotate: unknown interval %q, expected hourly or daily", cfg.Interval)
+	}
+
+	w := &Writer{
+		cfg:      cfg,
+		millCh:   make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	go w.millLoop()
+
+	// A file left over from an earlier period is rotated straight away
+	if w.cfg.Interval != "" && w.size > 0 {
+		if info, err := w.file.Stat(); err == nil && info.ModTime().Before(w.periodStart(time.Now())) {
+			if err := w.rotate(); err != nil {
+				w.file.Close()
+				close(w.millCh)
+				return nil, err
+			}
+		}
+	}
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	if w.due(len(p)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate moves the current file aside and starts a new one
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Reopen closes the file and opens it again by name, for use after an external tool
+// such as logrotate has moved it aside
+func (w *Writer) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+
+	// Open the file even if closing fails, the handle may already be closed by an
+	// earlier failed reopen and nothing else will replace it
+	closeErr := w.file.Close()
+	if err := w.open(); err != nil {
+		return err
+	}
+	if closeErr != nil && !errors.Is(closeErr, os.ErrClosed) {
+		return closeErr
+	}
+	return nil
+}
+
+// ReopenOnSignal calls Reopen whenever the process receives SIGHUP, as expected by
+// logrotate's default create mode. It returns a function that stops listening.
+func (w *Writer) ReopenOnSignal() func() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-hup:
+				w.Reopen()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(hup)
+		close(stop)
+		<-done
+	}
+}
+
+// Close closes the file, waiting for any compression and cleanup of rotated files to finish
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	err := w.file.Close()
+	close(w.millCh)
+	w.mu.Unlock()
+
+	<-w.millDone
+	return err
+}
+
+// due reports whether the file must be rotated before writing n more bytes
+func (w *Writer) due(n int) bool {
+	if w.cfg.MaxSize > 0 && w.size > 0 && w.size+int64(n) > w.cfg.MaxSize {
+		return true
+	}
+	return !w.nextRotate.IsZero() && !time.Now().Before(w.nextRotate)
+}
+
+// open opens the file for appending and works out when it next needs rotating
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.cfg.Filename), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(w.cfg.Filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.file = f
+	w.size = info.Size()
+	if w.cfg.Interval != "" {
+		w.nextRotate = w.nextPeriod(time.Now())
+	}
+	return nil
+}
+
+// rotate renames the current file to a backup and opens a new one, the caller must hold mu
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		// Keep writing to the file rather than to a closed handle
+		if openErr := w.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+
+	backup := w.backupName(time.Now())
+	if err := os.Rename(w.cfg.Filename, backup); err != nil && !os.IsNotExist(err) {
+		// Keep writing to the current file rather than failing every write from now on
+		if openErr := w.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	// A run already pending will see this backup too
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// millLoop runs the mill after each rotation until the Writer is closed, a single
+// goroutine so cleanup never removes a backup that is still being compressed
+func (w *Writer) millLoop() {
+	defer close(w.millDone)
+	for range w.millCh {
+		w.runMill()
+	}
+}
+
+// runMill removes backups beyond the limits and compresses the rest
+func (w *Writer) runMill() {
+	if w.cfg.MaxBackups <= 0 && w.cfg.MaxAge <= 0 && !w.cfg.Compress {
+		return
+	}
+
+	backups, err := w.backups()
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().Add(-w.cfg.MaxAge)
+	for i, b := range backups {
+		if (w.cfg.MaxBackups > 0 && i >= w.cfg.MaxBackups) || (w.cfg.MaxAge > 0 && b.at.Before(cutoff)) {
+			os.Remove(b.path)
+			continue
+		}
+		if w.cfg.Compress && !strings.HasSuffix(b.path, ".gz") {
+			if err := compressFile(b.path); err != nil {
+				fmt.Fprintf(os.Stderr, "logrotate: compressing %s: %v\n", b.path, err)
+			}
+		}
+	}
+}
+
+type backupFile struct {
+	path string
+	at   time.Time
+	seq  int // Suffix added when backups were made in the same millisecond
+}
+
+// backups returns the rotated files, newest first
+func (w *Writer) backups() ([]backupFile, error) {
+	dir := filepath.Dir(w.cfg.Filename)
+	prefix, ext := w.nameParts()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backupFile
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".gz")
+		if e.IsDir() || len(name) < len(prefix)+len(ext) || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := name[len(prefix) : len(name)-len(ext)]
+		seq := 0
+		if len(stamp) > len(backupTimeFormat) && stamp[len(backupTimeFormat)] == '-' {
+			if seq, err = strconv.Atoi(stamp[len(backupTimeFormat)+1:]); err != nil {
+				continue
+			}
+			stamp = stamp[:len(backupTimeFormat)]
+		}
+		at, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, e.Name()), at: at, seq: seq})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].at.Equal(backups[j].at) {
+			return backups[i].seq > backups[j].seq
+		}
+		return backups[i].at.After(backups[j].at)
+	})
+	return backups, nil
+}
+
+// backupName returns the path to rotate the file to, e.g. "app-2025-10-15T12-23-00.000.log",
+// adding a suffix such as "-1" if a backup from the same millisecond already exists
+func (w *Writer) backupName(t time.Time) string {
+	prefix, ext := w.nameParts()
+	stamp := prefix + t.Format(backupTimeFormat)
+	name := filepath.Join(filepath.Dir(w.cfg.Filename), stamp+ext)
+	for seq := 1; exists(name) || exists(name+".gz"); seq++ {
+		name = filepath.Join(filepath.Dir(w.cfg.Filename), stamp+"-"+strconv.Itoa(seq)+ext)
+	}
+	return name
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// nameParts splits the file name into the prefix and extension of its backups
+func (w *Writer) nameParts() (string, string) {
+	base := filepath.Base(w.cfg.Filename)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// periodStart returns the start of the hour or day containing t
+func (w *Writer) periodStart(t time.Time) time.Time {
+	if w.cfg.Interval == "hourly" {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// nextPeriod returns the start of the hour or day after the one containing t, days
+// aren't always 24 hours long so the wall clock is used rather than adding a duration
+func (w *Writer) nextPeriod(t time.Time) time.Time {
+	if w.cfg.Interval == "hourly" {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+}
+
+// compressFile gzips path to path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,217 @@
+package logrotate
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeLines writes n numbered lines of about 100 bytes
+func writeLines(t *testing.T, w *Writer, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		line := fmt.Sprintf("line %06d %s\n", i, strings.Repeat("x", 88))
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+}
+
+// readLines returns the lines of a log file or gzipped backup
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return lines
+}
+
+func TestSizeRotationKeepsEveryLine(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := New(Config{Filename: filename, MaxSize: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Bursts rotate several times within a millisecond, so backup names collide
+	writeLines(t, w, 500)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) < 20 {
+		t.Fatalf("got %d backups, want at least 20", len(backups))
+	}
+
+	seen := make(map[string]bool)
+	for _, path := range append([]string{filename}, backupPaths(backups)...) {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > 2000 {
+			t.Errorf("%s is %d bytes, want at most 2000", filepath.Base(path), info.Size())
+		}
+		for _, line := range readLines(t, path) {
+			seen[line] = true
+		}
+	}
+	if len(seen) != 500 {
+		t.Errorf("found %d distinct lines, want 500", len(seen))
+	}
+}
+
+func TestMaxBackupsAndCompress(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := New(Config{Filename: filename, MaxSize: 2000, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, w, 500)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compressed []string
+	for _, e := range entries {
+		switch {
+		case e.Name() == "app.log":
+		case strings.HasSuffix(e.Name(), ".log.gz"):
+			compressed = append(compressed, filepath.Join(dir, e.Name()))
+		default:
+			t.Errorf("unexpected file %s", e.Name())
+		}
+	}
+	if len(compressed) != 2 {
+		t.Fatalf("got %d compressed backups, want 2", len(compressed))
+	}
+
+	// The newest lines must survive, the current file holding the very last
+	last := readLines(t, filename)
+	if len(last) == 0 || !strings.HasPrefix(last[len(last)-1], "line 000499 ") {
+		t.Errorf("current file doesn't end with the last line written")
+	}
+	for _, path := range compressed {
+		if len(readLines(t, path)) == 0 {
+			t.Errorf("%s is empty", filepath.Base(path))
+		}
+	}
+}
+
+func TestBackupNameAddsSuffixOnCollision(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := New(Config{Filename: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	at := time.Date(2025, 10, 15, 12, 23, 0, 0, time.Local)
+	first := w.backupName(at)
+	if err := os.WriteFile(first, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	second := w.backupName(at)
+	if !strings.HasSuffix(second, "-1.log") {
+		t.Fatalf("got %s after %s, want a -1 suffix", filepath.Base(second), filepath.Base(first))
+	}
+	if err := os.WriteFile(second, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The suffixed backup is still recognised, and sorts as the newer of the two
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 || backups[0].path != second || backups[1].path != first {
+		t.Fatalf("got backups %v, want [%s %s]", backupPaths(backups), second, first)
+	}
+}
+
+func TestReopenRecoversAfterFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := New(Config{Filename: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// Replace the directory with a file so the log can't be opened
+	moved := dir + ".moved"
+	if err := os.Rename(dir, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reopen(); err == nil {
+		t.Fatal("Reopen succeeded without the directory")
+	}
+
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(moved, dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reopen(); err != nil {
+		t.Fatalf("Reopen after restoring the directory: %v", err)
+	}
+	if _, err := w.Write([]byte("recovered\n")); err != nil {
+		t.Fatalf("Write after Reopen: %v", err)
+	}
+	if lines := readLines(t, filename); len(lines) != 1 || lines[0] != "recovered" {
+		t.Errorf("got %q, want the line written after recovering", lines)
+	}
+}
+
+func backupPaths(backups []backupFile) []string {
+	paths := make([]string, len(backups))
+	for i, b := range backups {
+		paths[i] = b.path
+	}
+	return paths
+}
@@ -0,0 +1,310 @@
+package logger
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const samplingSlots = 4096 // Counters per level, messages are hashed into these
+
+// SamplingPolicy controls how entries at one level are sampled. Either the first
+// First entries with the same message are written each Interval and then every
+// Thereafter-th, or if Probability is set each entry is written with that probability.
+type SamplingPolicy struct {
+	First       int           // Entries per message written in full each interval
+	Thereafter  int           // After First, write every Thereafter-th entry, 0 drops the rest
+	Interval    time.Duration // Counting period, defaults to 1 second
+	Probability float64       // If > 0, write entries with this probability (0-1) instead of counting
+}
+
+// SamplingConfig for creating a new SamplingLogger
+type SamplingConfig struct {
+	Levels         map[Level]SamplingPolicy // Levels without a policy are not sampled, fatal never is
+	ReportInterval time.Duration            // If > 0, log a summary of dropped entries this often
+}
+
+// SamplingStats holds the counters for one level
+type SamplingStats struct {
+	Written uint64
+	Dropped uint64
+}
+
+// SamplingLogger wraps a Logger and drops entries on high-volume paths according to
+// per-level policies. Child loggers share the counters of the logger they came from.
+type SamplingLogger struct {
+	logger Logger
+	state  *samplingState
+}
+
+type samplingState struct {
+	logger   Logger // Root logger, used for summary entries
+	policies map[Level]SamplingPolicy
+	counters map[Level]*[samplingSlots]samplingCounter
+	written  [LevelFatal + 1]atomic.Uint64
+	dropped  [LevelFatal + 1]atomic.Uint64
+	reported [LevelFatal + 1]uint64 // Dropped counts at the last summary, guarded by mu
+	mu       sync.Mutex
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+type samplingCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// NewSamplingLogger creates a new SamplingLogger around l. Call Close to stop the
+// periodic summary when ReportInterval is set.
+func NewSamplingLogger(l Logger, cfg SamplingConfig) *SamplingLogger {
+	state := &samplingState{
+		logger:   l,
+		policies: make(map[Level]SamplingPolicy, len(cfg.Levels)),
+		counters: make(map[Level]*[samplingSlots]samplingCounter, len(cfg.Levels)),
+	}
+	for level, policy := range cfg.Levels {
+		if level < LevelTrace || level >= LevelFatal {
+			continue
+		}
+		if policy.Interval <= 0 {
+			policy.Interval = time.Second
+		}
+		state.policies[level] = policy
+		if policy.Probability <= 0 {
+			state.counters[level] = &[samplingSlots]samplingCounter{}
+		}
+	}
+
+	if cfg.ReportInterval > 0 {
+		state.stop = make(chan struct{})
+		state.done = make(chan struct{})
+		go state.reportLoop(cfg.ReportInterval)
+	}
+
+	return &SamplingLogger{
+		logger: l,
+		state:  state,
+	}
+}
+
+// Stats returns the number of entries written and dropped at each sampled level
+func (s *SamplingLogger) Stats() map[Level]SamplingStats {
+	stats := make(map[Level]SamplingStats, len(s.state.policies))
+	for level := range s.state.policies {
+		stats[level] = SamplingStats{
+			Written: s.state.written[level].Load(),
+			Dropped: s.state.dropped[level].Load(),
+		}
+	}
+	return stats
+}
+
+// Close stops the periodic summary, writing a final one if entries were dropped since the last
+func (s *SamplingLogger) Close() error {
+	if s.state.stop == nil {
+		return nil
+	}
+
+	s.state.mu.Lock()
+	select {
+	case <-s.state.stop:
+	default:
+		close(s.state.stop)
+	}
+	s.state.mu.Unlock()
+
+	<-s.state.done
+	return nil
+}
+
+func (st *samplingState) reportLoop(interval time.Duration) {
+	defer close(st.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			st.report()
+		case <-st.stop:
+			st.report()
+			return
+		}
+	}
+}
+
+// report logs the number of entries dropped at each level since the last report
+func (st *samplingState) report() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	var keysAndValues []any
+	for level := LevelTrace; level < LevelFatal; level++ {
+		dropped := st.dropped[level].Load()
+		if n := dropped - st.reported[level]; n > 0 {
+			keysAndValues = append(keysAndValues, level.String(), n)
+		}
+		st.reported[level] = dropped
+	}
+
+	if len(keysAndValues) > 0 {
+		st.logger.Warn("log entries dropped by sampling", keysAndValues...)
+	}
+}
+
+// sample reports whether an entry should be written and updates the counters
+func (st *samplingState) sample(l Logger, level Level, msg string) bool {
+	policy, ok := st.policies[level]
+	if !ok {
+		return true
+	}
+
+	// Entries the logger would discard anyway don't count towards sampling
+	if !IsEnabled(l, level) {
+		return false
+	}
+
+	var keep bool
+	if policy.Probability > 0 {
+		keep = rand.Float64() < policy.Probability
+	} else {
+		h := fnv.New32a()
+		h.Write([]byte(msg))
+		n := st.counters[level][h.Sum32()%samplingSlots].inc(time.Now(), policy.Interval)
+		first := uint64(max(policy.First, 0))
+		keep = n <= first || (policy.Thereafter > 0 && (n-first)%uint64(policy.Thereafter) == 0)
+	}
+
+	if keep {
+		st.written[level].Add(1)
+	} else {
+		st.dropped[level].Add(1)
+	}
+	return keep
+}
+
+// inc increments the counter, resetting it first if the interval has passed
+func (c *samplingCounter) inc(now time.Time, interval time.Duration) uint64 {
+	tn := now.UnixNano()
+	resetAt := c.resetAt.Load()
+	if resetAt > tn {
+		return c.count.Add(1)
+	}
+
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, tn+interval.Nanoseconds()) {
+		// Another goroutine started the new interval first
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+func (s *SamplingLogger) Trace(msg string, keysAndValues ...any) {
+	s.Log(nil, LevelTrace, msg, keysAndValues...)
+}
+
+func (s *SamplingLogger) Debug(msg string, keysAndValues ...any) {
+	s.Log(nil, LevelDebug, msg, keysAndValues...)
+}
+
+func (s *SamplingLogger) Info(msg string, keysAndValues ...any) {
+	s.Log(nil, LevelInfo, msg, keysAndValues...)
+}
+
+func (s *SamplingLogger) Warn(msg string, keysAndValues ...any) {
+	s.Log(nil, LevelWarn, msg, keysAndValues...)
+}
+
+func (s *SamplingLogger) Error(msg string, keysAndValues ...any) {
+	s.Log(nil, LevelError, msg, keysAndValues...)
+}
+
+func (s *SamplingLogger) Fatal(msg string, keysAndValues ...any) {
+	s.logger.Fatal(msg, keysAndValues...)
+}
+
+func (s *SamplingLogger) TraceContext(ctx context.Context, msg string, keysAndValues ...any) {
+	s.Log(ctx, LevelTrace, msg, keysAndValues...)
+}
+
+func (s *SamplingLogger) DebugContext(ctx context.Context, msg string, keysAndValues ...any) {
+	s.Log(ctx, LevelDebug, msg, keysAndValues...)
+}
+
+func (s *SamplingLogger) InfoContext(ctx context.Context, msg string, keysAndValues ...any) {
+	s.Log(ctx, LevelInfo, msg, keysAndValues...)
+}
+
+func (s *SamplingLogger) WarnContext(ctx context.Context, msg string, keysAndValues ...any) {
+	s.Log(ctx, LevelWarn, msg, keysAndValues...)
+}
+
+func (s *SamplingLogger) ErrorContext(ctx context.Context, msg string, keysAndValues ...any) {
+	s.Log(ctx, LevelError, msg, keysAndValues...)
+}
+
+func (s *SamplingLogger) FatalContext(ctx context.Context, msg string, keysAndValues ...any) {
+	if cl, ok := s.logger.(ContextLogger); ok {
+		cl.FatalContext(ctx, msg, keysAndValues...)
+		return
+	}
+	s.logger.Fatal(msg, keysAndValues...)
+}
+
+// Log writes an entry at the given level if it survives sampling, without exiting for LevelFatal
+func (s *SamplingLogger) Log(ctx context.Context, level Level, msg string, keysAndValues ...any) {
+	if s.state.sample(s.logger, level, msg) {
+		LogAt(s.logger, ctx, level, msg, keysAndValues...)
+	}
+}
+
+// Flush flushes the wrapped logger if it implements Flusher
+func (s *SamplingLogger) Flush(ctx context.Context) error {
+	return flush(ctx, s.logger)
+}
+
+// Enabled reports whether the wrapped logger would write an entry at the given level
+func (s *SamplingLogger) Enabled(level Level) bool {
+	return IsEnabled(s.logger, level)
+}
+
+// SetLevel sets the level of the wrapped logger if it implements LevelSetter
+func (s *SamplingLogger) SetLevel(level string) error {
+	return setLevel(s.logger, level)
+}
+
+// Level returns the level of the wrapped logger
+func (s *SamplingLogger) Level() string {
+	return levelOf(s.logger)
+}
+
+// SetGroupLevel sets the level for the group on the wrapped logger if it implements GroupLevelSetter
+func (s *SamplingLogger) SetGroupLevel(group, level string) error {
+	return setGroupLevel(s.logger, group, level)
+}
+
+// ClearGroupLevel removes the override for the group from the wrapped logger
+func (s *SamplingLogger) ClearGroupLevel(group string) {
+	clearGroupLevel(s.logger, group)
+}
+
+// GroupLevels returns the group overrides of the wrapped logger
+func (s *SamplingLogger) GroupLevels() map[string]string {
+	return groupLevelsOf(s.logger)
+}
+
+func (s *SamplingLogger) With(key string, value any) Logger {
+	return &SamplingLogger{logger: s.logger.With(key, value), state: s.state}
+}
+
+func (s *SamplingLogger) WithError(err error) Logger {
+	return &SamplingLogger{logger: s.logger.WithError(err), state: s.state}
+}
+
+func (s *SamplingLogger) WithGroup(group string) Logger {
+	return &SamplingLogger{logger: s.logger.WithGroup(group), state: s.state}
+}
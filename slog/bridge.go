@@ -0,0 +1,116 @@
+package logslog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/paularlott/logger"
+)
+
+// LoggerHandler is a slog.Handler that forwards records to a logger.Logger, so code
+// that logs through slog ends up in the same pipeline as everything else whatever
+// the backend. Attributes in slog groups are flattened to dotted keys, e.g. "req.id".
+type LoggerHandler struct {
+	logger logger.Logger
+	prefix string // Dotted slog group names followed by "."
+}
+
+// NewLoggerHandler creates a new slog.Handler writing to l
+func NewLoggerHandler(l logger.Logger) *LoggerHandler {
+	return &LoggerHandler{logger: l}
+}
+
+// SetDefault makes l the destination for slog.Default, and therefore also the
+// standard library log package, returning a function that restores the previous default
+func SetDefault(l logger.Logger) func() {
+	previous := slog.Default()
+	slog.SetDefault(slog.New(NewLoggerHandler(l)))
+	return func() {
+		slog.SetDefault(previous)
+	}
+}
+
+func (h *LoggerHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return logger.IsEnabled(h.logger, fromSlogLevel(level))
+}
+
+func (h *LoggerHandler) Handle(ctx context.Context, r slog.Record) error {
+	keysAndValues := make([]any, 0, r.NumAttrs()*2)
+	r.Attrs(func(a slog.Attr) bool {
+		keysAndValues = appendKeyValue(keysAndValues, h.prefix, a)
+		return true
+	})
+
+	// slog has no notion of exiting, so fatal records never terminate the process
+	logger.LogAt(h.logger, ctx, fromSlogLevel(r.Level), r.Message, keysAndValues...)
+	return nil
+}
+
+func (h *LoggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	l := h.logger
+	for _, a := range attrs {
+		kv := appendKeyValue(nil, h.prefix, a)
+		for i := 0; i+1 < len(kv); i += 2 {
+			l = l.With(kv[i].(string), kv[i+1])
+		}
+	}
+
+	return &LoggerHandler{
+		logger: l,
+		prefix: h.prefix,
+	}
+}
+
+func (h *LoggerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &LoggerHandler{
+		logger: h.logger,
+		prefix: h.prefix + name + ".",
+	}
+}
+
+// appendKeyValue flattens a slog attribute into key-value pairs
+func appendKeyValue(keysAndValues []any, prefix string, a slog.Attr) []any {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return keysAndValues
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix = prefix + a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			keysAndValues = appendKeyValue(keysAndValues, groupPrefix, ga)
+		}
+		return keysAndValues
+	}
+
+	return append(keysAndValues, prefix+a.Key, a.Value.Any())
+}
+
+// fromSlogLevel maps a slog level, including LevelTrace and LevelFatal, to the nearest logger.Level
+func fromSlogLevel(level slog.Level) logger.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return logger.LevelTrace
+	case level < slog.LevelInfo:
+		return logger.LevelDebug
+	case level < slog.LevelWarn:
+		return logger.LevelInfo
+	case level < slog.LevelError:
+		return logger.LevelWarn
+	case level < LevelFatal:
+		return logger.LevelError
+	default:
+		return logger.LevelFatal
+	}
+}
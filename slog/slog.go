@@ -2,10 +2,14 @@ package logslog
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/paularlott/logger"
 )
@@ -16,11 +20,64 @@ const LevelTrace = slog.Level(-8)
 // Custom slog level for FATAL (above ERROR which is 8)
 const LevelFatal = slog.Level(10)
 
-// SlogLogger wraps slog.Logger to implement the logger.Logger interface
-// SlogLogger wraps slog.Logger to implement the logger.Logger interface
+// SlogLogger wraps slog.Logger to implement the logger.Logger and logger.ContextLogger interfaces
 type SlogLogger struct {
 	logger         *slog.Logger
+	levels         *levels // Shared by the root logger and all of its children
+	group          string
 	groupFieldName string
+	lazy           []any                // Lazy key-value pairs from With, added to each entry written
+	source         *logger.SourceConfig // nil unless AddCaller or StackTrace is set
+	err            error                // From WithError, checked for a recorded stack
+}
+
+// levels holds the minimum level and any per-group overrides, shared by a root
+// logger and all of its children
+type levels struct {
+	global slog.LevelVar
+	mu     sync.Mutex                            // Serialises updates to groups
+	groups atomic.Pointer[map[string]slog.Level] // Replaced on write, never modified
+}
+
+// Level implements slog.Leveler, returning the lowest level that any group may
+// log at so the handler lets through everything the logger decides to emit
+func (lv *levels) Level() slog.Level {
+	minLevel := lv.global.Level()
+	if groups := lv.groups.Load(); groups != nil {
+		for _, level := range *groups {
+			if level < minLevel {
+				minLevel = level
+			}
+		}
+	}
+	return minLevel
+}
+
+// forGroup returns the minimum level for the given group
+func (lv *levels) forGroup(group string) slog.Level {
+	if group != "" {
+		if groups := lv.groups.Load(); groups != nil {
+			if level, ok := (*groups)[group]; ok {
+				return level
+			}
+		}
+	}
+	return lv.global.Level()
+}
+
+// update replaces the group overrides with a modified copy
+func (lv *levels) update(fn func(groups map[string]slog.Level)) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	groups := make(map[string]slog.Level)
+	if old := lv.groups.Load(); old != nil {
+		for k, v := range *old {
+			groups[k] = v
+		}
+	}
+	fn(groups)
+	lv.groups.Store(&groups)
 }
 
 // Config for creating a new SlogLogger
@@ -29,13 +86,44 @@ type Config struct {
 	Format         string    // "console" or "json"
 	Writer         io.Writer // Output writer, defaults to os.Stdout
 	GroupFieldName string    // Field name for groups, defaults to "_group"
+	NoColor        bool      // Disable colors in console output
+	AddCaller      bool      // Add the file and line of the log call to each entry
+	CallerSkip     int       // Extra frames to skip when the application wraps the logger
+	StackTrace     bool      // Add a stack trace to error and fatal entries
+
+	// LevelOverrides sets the level for loggers created with WithGroup, keyed by group name,
+	// e.g. {"database": "trace", "http": "warn"}. See logger.ParseLevelOverrides for the string form.
+	LevelOverrides map[string]string
 }
 
-// New creates a new SlogLogger with the given configuration
+func init() {
+	logger.Register("slog", func(cfg logger.Config) (logger.Logger, error) {
+		return NewWithError(ConfigFrom(cfg))
+	})
+}
+
+// ConfigFrom converts a backend-independent logger.Config, e.g. from logger.ConfigFromEnv
+func ConfigFrom(cfg logger.Config) Config {
+	return Config{
+		Level:          cfg.Level,
+		Format:         cfg.Format,
+		Writer:         cfg.Writer,
+		GroupFieldName: cfg.GroupFieldName,
+		NoColor:        cfg.NoColor,
+		AddCaller:      cfg.AddCaller,
+		CallerSkip:     cfg.CallerSkip,
+		StackTrace:     cfg.StackTrace,
+		LevelOverrides: cfg.LevelOverrides,
+	}
+}
+
+// New creates a new SlogLogger with the given configuration. Unknown levels fall back
+// to info and unknown formats to console, use NewWithError to reject them instead.
 func New(cfg Config) logger.Logger {
 	if cfg.Writer == nil {
 		cfg.Writer = os.Stdout
 	}
+	cfg.Format = strings.ToLower(cfg.Format)
 	if cfg.Format == "" {
 		cfg.Format = "console"
 	}
@@ -46,9 +134,17 @@ func New(cfg Config) logger.Logger {
 		cfg.GroupFieldName = "_group"
 	}
 
-	level := parseLevel(cfg.Level)
+	levels := &levels{}
+	levels.global.Set(parseLevel(cfg.Level))
+	if len(cfg.LevelOverrides) > 0 {
+		levels.update(func(groups map[string]slog.Level) {
+			for group, level := range cfg.LevelOverrides {
+				groups[group] = parseLevel(level)
+			}
+		})
+	}
 	opts := &slog.HandlerOptions{
-		Level: level,
+		Level: levels,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
 			// Replace custom level names for TRACE and FATAL in JSON output
 			if a.Key == slog.LevelKey {
@@ -69,81 +165,322 @@ func New(cfg Config) logger.Logger {
 			handler: slog.NewJSONHandler(cfg.Writer, opts),
 		}
 	} else {
-		handler = NewConsoleHandler(cfg.Writer, opts, cfg.GroupFieldName)
+		console := NewConsoleHandler(cfg.Writer, opts, cfg.GroupFieldName)
+		console.noColor = cfg.NoColor
+		handler = console
+	}
+
+	var source *logger.SourceConfig
+	if cfg.AddCaller || cfg.StackTrace {
+		source = &logger.SourceConfig{
+			Caller: cfg.AddCaller,
+			Stack:  cfg.StackTrace,
+			Skip:   cfg.CallerSkip,
+			JSON:   cfg.Format == "json",
+		}
 	}
 
 	return &SlogLogger{
 		logger:         slog.New(handler),
+		levels:         levels,
 		groupFieldName: cfg.GroupFieldName,
+		source:         source,
 	}
 }
 
+// NewWithError creates a new SlogLogger like New, but returns an error for an unknown
+// level, format or level override instead of falling back to the defaults
+func NewWithError(cfg Config) (logger.Logger, error) {
+	if cfg.Level != "" {
+		if _, err := ParseLevel(cfg.Level); err != nil {
+			return nil, err
+		}
+	}
+	switch strings.ToLower(cfg.Format) {
+	case "", "console", "json":
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected console or json", cfg.Format)
+	}
+	for group, level := range cfg.LevelOverrides {
+		if _, err := ParseLevel(level); err != nil {
+			return nil, fmt.Errorf("level override for group %q: %w", group, err)
+		}
+	}
+	return New(cfg), nil
+}
+
+// ParseLevel converts a level name such as "debug" to a slog.Level
+func ParseLevel(level string) (slog.Level, error) {
+	if l, ok := lookupLevel(level); ok {
+		return l, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+}
+
 func parseLevel(level string) slog.Level {
+	if l, ok := lookupLevel(level); ok {
+		return l
+	}
+	return slog.LevelInfo
+}
+
+func lookupLevel(level string) (slog.Level, bool) {
 	switch strings.ToLower(level) {
 	case "trace":
-		return LevelTrace
+		return LevelTrace, true
 	case "debug":
-		return slog.LevelDebug
+		return slog.LevelDebug, true
 	case "info":
-		return slog.LevelInfo
+		return slog.LevelInfo, true
 	case "warn", "warning":
-		return slog.LevelWarn
+		return slog.LevelWarn, true
 	case "error":
-		return slog.LevelError
+		return slog.LevelError, true
 	case "fatal":
-		return LevelFatal
+		return LevelFatal, true
 	default:
+		return slog.LevelInfo, false
+	}
+}
+
+func levelName(level slog.Level) string {
+	switch level {
+	case LevelTrace:
+		return "trace"
+	case slog.LevelDebug:
+		return "debug"
+	case slog.LevelInfo:
+		return "info"
+	case slog.LevelWarn:
+		return "warn"
+	case slog.LevelError:
+		return "error"
+	case LevelFatal:
+		return "fatal"
+	default:
+		return strings.ToLower(level.String())
+	}
+}
+
+// SetLevel changes the minimum level at runtime, affecting this logger, its parent and all children
+func (l *SlogLogger) SetLevel(level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.levels.global.Set(lvl)
+	return nil
+}
+
+// Level returns the current minimum level
+func (l *SlogLogger) Level() string {
+	return levelName(l.levels.global.Level())
+}
+
+// SetGroupLevel overrides the minimum level for loggers created with WithGroup(group)
+func (l *SlogLogger) SetGroupLevel(group, level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.levels.update(func(groups map[string]slog.Level) {
+		groups[group] = lvl
+	})
+	return nil
+}
+
+// ClearGroupLevel removes the level override for the group
+func (l *SlogLogger) ClearGroupLevel(group string) {
+	l.levels.update(func(groups map[string]slog.Level) {
+		delete(groups, group)
+	})
+}
+
+// GroupLevels returns the current per-group level overrides
+func (l *SlogLogger) GroupLevels() map[string]string {
+	result := make(map[string]string)
+	if groups := l.levels.groups.Load(); groups != nil {
+		for group, level := range *groups {
+			result[group] = levelName(level)
+		}
+	}
+	return result
+}
+
+// Enabled reports whether an entry at the given level would be written
+func (l *SlogLogger) Enabled(level logger.Level) bool {
+	lvl := toSlogLevel(level)
+	if lvl < l.levels.forGroup(l.group) {
+		return false
+	}
+	return l.logger.Handler().Enabled(context.Background(), lvl)
+}
+
+// Log writes an entry at the given level, without exiting for logger.LevelFatal
+func (l *SlogLogger) Log(ctx context.Context, level logger.Level, msg string, keysAndValues ...any) {
+	l.log(ctx, toSlogLevel(level), msg, keysAndValues...)
+}
+
+func toSlogLevel(level logger.Level) slog.Level {
+	switch level {
+	case logger.LevelTrace:
+		return LevelTrace
+	case logger.LevelDebug:
+		return slog.LevelDebug
+	case logger.LevelInfo:
 		return slog.LevelInfo
+	case logger.LevelWarn:
+		return slog.LevelWarn
+	case logger.LevelError:
+		return slog.LevelError
+	default:
+		return LevelFatal
 	}
 }
 
 func (l *SlogLogger) Trace(msg string, keysAndValues ...any) {
-	l.log(LevelTrace, msg, keysAndValues...)
+	l.log(context.Background(), LevelTrace, msg, keysAndValues...)
 }
 
 func (l *SlogLogger) Debug(msg string, keysAndValues ...any) {
-	l.log(slog.LevelDebug, msg, keysAndValues...)
+	l.log(context.Background(), slog.LevelDebug, msg, keysAndValues...)
 }
 
 func (l *SlogLogger) Info(msg string, keysAndValues ...any) {
-	l.log(slog.LevelInfo, msg, keysAndValues...)
+	l.log(context.Background(), slog.LevelInfo, msg, keysAndValues...)
 }
 
 func (l *SlogLogger) Warn(msg string, keysAndValues ...any) {
-	l.log(slog.LevelWarn, msg, keysAndValues...)
+	l.log(context.Background(), slog.LevelWarn, msg, keysAndValues...)
 }
 
 func (l *SlogLogger) Error(msg string, keysAndValues ...any) {
-	l.log(slog.LevelError, msg, keysAndValues...)
+	l.log(context.Background(), slog.LevelError, msg, keysAndValues...)
 }
 
 func (l *SlogLogger) Fatal(msg string, keysAndValues ...any) {
-	l.log(LevelFatal, msg, keysAndValues...)
-	os.Exit(1)
+	l.log(context.Background(), LevelFatal, msg, keysAndValues...)
+	logger.Exit(logger.ExitCode(keysAndValues))
+}
+
+func (l *SlogLogger) TraceContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.log(ctx, LevelTrace, msg, keysAndValues...)
+}
+
+func (l *SlogLogger) DebugContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.log(ctx, slog.LevelDebug, msg, keysAndValues...)
+}
+
+func (l *SlogLogger) InfoContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.log(ctx, slog.LevelInfo, msg, keysAndValues...)
+}
+
+func (l *SlogLogger) WarnContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.log(ctx, slog.LevelWarn, msg, keysAndValues...)
 }
 
-func (l *SlogLogger) log(level slog.Level, msg string, keysAndValues ...any) {
-	l.logger.Log(context.Background(), level, msg, keysAndValues...)
+func (l *SlogLogger) ErrorContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.log(ctx, slog.LevelError, msg, keysAndValues...)
+}
+
+func (l *SlogLogger) FatalContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.log(ctx, LevelFatal, msg, keysAndValues...)
+	logger.Exit(logger.ExitCode(keysAndValues))
+}
+
+func (l *SlogLogger) log(ctx context.Context, level slog.Level, msg string, keysAndValues ...any) {
+	if level < l.levels.forGroup(l.group) {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	args := keysAndValues
+	if len(l.lazy) > 0 {
+		args = append(l.lazy[:len(l.lazy):len(l.lazy)], keysAndValues...)
+	}
+	if l.source != nil {
+		args = append(args[:len(args):len(args)], l.source.Fields(ctx, fromSlogLevel(level), l.err, keysAndValues)...)
+	}
+	l.logger.Log(ctx, level, msg, lazyArgs(args)...)
+}
+
+// lazyValuer adapts a logger.LazyValue to slog so it is resolved by the handler
+type lazyValuer struct {
+	value logger.LazyValue
+}
+
+func (v lazyValuer) LogValue() slog.Value {
+	return slog.AnyValue(v.value.Value())
+}
+
+// lazyArgs replaces any logger.LazyValue in args with a slog.LogValuer, copying args only if needed
+func lazyArgs(args []any) []any {
+	var out []any
+	for i, arg := range args {
+		if v, ok := arg.(logger.LazyValue); ok {
+			if out == nil {
+				out = make([]any, len(args))
+				copy(out, args)
+			}
+			out[i] = lazyValuer{value: v}
+		}
+	}
+	if out == nil {
+		return args
+	}
+	return out
 }
 
 func (l *SlogLogger) With(key string, value any) logger.Logger {
+	if v, ok := value.(logger.LazyValue); ok {
+		// Handlers resolve attributes as they are added, so keep lazy values back until an entry is written
+		lazy := make([]any, len(l.lazy), len(l.lazy)+2)
+		copy(lazy, l.lazy)
+		return &SlogLogger{
+			logger:         l.logger,
+			levels:         l.levels,
+			group:          l.group,
+			groupFieldName: l.groupFieldName,
+			lazy:           append(lazy, key, lazyValuer{value: v}),
+			source:         l.source,
+			err:            l.err,
+		}
+	}
+
 	return &SlogLogger{
 		logger:         l.logger.With(key, value),
+		levels:         l.levels,
+		group:          l.group,
 		groupFieldName: l.groupFieldName,
+		lazy:           l.lazy,
+		source:         l.source,
+		err:            l.err,
 	}
 }
 
 func (l *SlogLogger) WithError(err error) logger.Logger {
 	return &SlogLogger{
 		logger:         l.logger.With("error", err),
+		levels:         l.levels,
+		group:          l.group,
 		groupFieldName: l.groupFieldName,
+		lazy:           l.lazy,
+		source:         l.source,
+		err:            err,
 	}
 }
 
 func (l *SlogLogger) WithGroup(group string) logger.Logger {
 	return &SlogLogger{
 		logger:         l.logger.With(l.groupFieldName, group),
+		levels:         l.levels,
+		group:          group,
 		groupFieldName: l.groupFieldName,
+		lazy:           l.lazy,
+		source:         l.source,
+		err:            l.err,
 	}
 }
 
@@ -179,6 +516,7 @@ type ConsoleHandler struct {
 	attrs          []slog.Attr
 	groups         []string
 	groupFieldName string
+	noColor        bool
 }
 
 // NewConsoleHandler creates a new console handler with colored output
@@ -207,16 +545,12 @@ func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
 	var buf strings.Builder
 
 	// Date and time with timezone: "15 Oct 25 12:23 AWST"
-	buf.WriteString("\033[90m")
-	buf.WriteString(r.Time.Format("02 Jan 06 15:04 MST"))
-	buf.WriteString("\033[0m ")
+	writeColored(&buf, h.noColor, "\033[90m", r.Time.Format("02 Jan 06 15:04 MST"))
+	buf.WriteString(" ")
 
 	// Level with color
-	levelColor := getLevelColor(r.Level)
-	levelStr := getLevelString(r.Level)
-	buf.WriteString(levelColor)
-	buf.WriteString(levelStr)
-	buf.WriteString("\033[0m ")
+	writeColored(&buf, h.noColor, getLevelColor(r.Level), getLevelString(r.Level))
+	buf.WriteString(" ")
 
 	// Group in brackets if present (from handler attrs or record attrs)
 	var group string
@@ -241,9 +575,8 @@ func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
 	}
 
 	if group != "" {
-		buf.WriteString("\033[36m[")
-		buf.WriteString(group)
-		buf.WriteString("]\033[0m ")
+		writeColored(&buf, h.noColor, "\033[36m", "["+group+"]")
+		buf.WriteString(" ")
 	}
 
 	// Message
@@ -252,24 +585,34 @@ func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
 	// Handler-level attributes (skip group field as it's already displayed)
 	for _, attr := range h.attrs {
 		if attr.Key != h.groupFieldName {
-			appendAttr(&buf, attr, h.groups)
+			appendAttr(&buf, attr, h.groups, h.noColor)
 		}
 	}
 
-	// Record attributes (skip group field as it's already displayed)
+	// Record attributes (skip group field as it's already displayed, stack goes below the line)
+	var stack []logger.StackFrame
 	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == logger.StackKey {
+			if frames, ok := a.Value.Any().([]logger.StackFrame); ok {
+				stack = frames
+				return true
+			}
+		}
 		if a.Key != h.groupFieldName {
-			appendAttr(&buf, a, h.groups)
+			appendAttr(&buf, a, h.groups, h.noColor)
 		}
 		return true
 	})
 
 	buf.WriteString("\n")
+	appendStack(&buf, stack, h.noColor)
 	_, err := h.writer.Write([]byte(buf.String()))
 	return err
 }
 
-func appendAttr(buf *strings.Builder, attr slog.Attr, groups []string) {
+func appendAttr(buf *strings.Builder, attr slog.Attr, groups []string, noColor bool) {
+	attr.Value = attr.Value.Resolve()
+
 	// Handle group nesting
 	key := attr.Key
 	if len(groups) > 0 {
@@ -279,17 +622,39 @@ func appendAttr(buf *strings.Builder, attr slog.Attr, groups []string) {
 	// Handle group attributes
 	if attr.Value.Kind() == slog.KindGroup {
 		for _, groupAttr := range attr.Value.Group() {
-			appendAttr(buf, groupAttr, append(groups, attr.Key))
+			appendAttr(buf, groupAttr, append(groups, attr.Key), noColor)
 		}
 		return
 	}
 
-	buf.WriteString(" \033[36m")
-	buf.WriteString(key)
-	buf.WriteString("\033[0m=")
+	buf.WriteString(" ")
+	writeColored(buf, noColor, "\033[36m", key)
+	buf.WriteString("=")
 	buf.WriteString(attr.Value.String())
 }
 
+// appendStack writes each frame as an indented function name followed by its file and line
+func appendStack(buf *strings.Builder, stack []logger.StackFrame, noColor bool) {
+	for _, frame := range stack {
+		buf.WriteString("    ")
+		buf.WriteString(frame.Function)
+		buf.WriteString("\n        ")
+		writeColored(buf, noColor, "\033[90m", frame.File+":"+strconv.Itoa(frame.Line))
+		buf.WriteString("\n")
+	}
+}
+
+// writeColored writes s in the given ANSI color, or plain when colors are disabled
+func writeColored(buf *strings.Builder, noColor bool, color, s string) {
+	if noColor {
+		buf.WriteString(s)
+		return
+	}
+	buf.WriteString(color)
+	buf.WriteString(s)
+	buf.WriteString("\033[0m")
+}
+
 func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	newAttrs := make([]slog.Attr, len(h.attrs)+len(attrs))
 	copy(newAttrs, h.attrs)
@@ -301,6 +666,7 @@ func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 		attrs:          newAttrs,
 		groups:         h.groups,
 		groupFieldName: h.groupFieldName,
+		noColor:        h.noColor,
 	}
 }
 
@@ -319,6 +685,7 @@ func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
 		attrs:          h.attrs,
 		groups:         newGroups,
 		groupFieldName: h.groupFieldName,
+		noColor:        h.noColor,
 	}
 }
 
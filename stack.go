@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// StackKey is the key stack traces are added to entries under
+const StackKey = "stack"
+
+const maxStackDepth = 64
+
+var frameType = reflect.TypeOf(runtime.Frame{})
+
+// StackFrame is one frame of a stack trace
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// CaptureStack returns the stack of the current goroutine starting at the code that
+// called into the logger, skipping frames in the same way as Caller
+func CaptureStack(skip int) []StackFrame {
+	return stackFrom(callSite(nil), skip)
+}
+
+// stackFrom returns the stack CaptureStack would for the given program counters
+func stackFrom(pcs []uintptr, skip int) []StackFrame {
+	var stack []StackFrame
+	frames := runtime.CallersFrames(pcs)
+	for more := true; more; {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+
+		switch {
+		case stack == nil && isLoggerFrame(frame.Function):
+			// Still inside the logging packages
+		case stack == nil && skip > 0:
+			skip--
+		case frame.Function != "" && !isRuntimeFrame(frame.Function):
+			stack = append(stack, toStackFrame(frame))
+		}
+	}
+	return stack
+}
+
+// ErrorStack returns the stack recorded by err, or by the deepest error it wraps that
+// records one, nil if there is none. An error records a stack by having a StackTrace
+// method returning program counters, as github.com/pkg/errors and compatible packages
+// do, or a []runtime.Frame.
+func ErrorStack(err error) []StackFrame {
+	var stack []StackFrame
+	for err != nil && !isNilPointer(err) {
+		if s := stackOf(err); s != nil {
+			stack = s
+		}
+
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Cause() error }:
+			err = e.Cause()
+		default:
+			err = nil
+		}
+	}
+	return stack
+}
+
+// stackOf calls the StackTrace method of err if it has one. Reflection is used so
+// packages such as github.com/pkg/errors, whose StackTrace returns their own named
+// slice type, are supported without depending on them. A method that panics is
+// treated as having no stack rather than failing the log call.
+func stackOf(err error) (stack []StackFrame) {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+
+	defer func() {
+		if recover() != nil {
+			stack = nil
+		}
+	}()
+	trace := method.Call(nil)[0]
+	if trace.Kind() != reflect.Slice || trace.Len() == 0 {
+		return nil
+	}
+
+	switch {
+	case trace.Type().Elem().Kind() == reflect.Uintptr:
+		pcs := make([]uintptr, trace.Len())
+		for i := range pcs {
+			pcs[i] = uintptr(trace.Index(i).Uint())
+		}
+
+		var stack []StackFrame
+		frames := runtime.CallersFrames(pcs)
+		for more := true; more; {
+			var frame runtime.Frame
+			frame, more = frames.Next()
+			if frame.Function != "" && !isRuntimeFrame(frame.Function) {
+				stack = append(stack, toStackFrame(frame))
+			}
+		}
+		return stack
+
+	case trace.Type().Elem() == frameType:
+		stack := make([]StackFrame, 0, trace.Len())
+		for i := 0; i < trace.Len(); i++ {
+			stack = append(stack, toStackFrame(trace.Index(i).Interface().(runtime.Frame)))
+		}
+		return stack
+	}
+	return nil
+}
+
+// StackFor returns the stack to attach to an error entry: that recorded by err or by
+// any error in keysAndValues, otherwise the stack of the log call, taken from ctx for
+// entries queued by AsyncLogger
+func StackFor(ctx context.Context, err error, skip int, keysAndValues []any) []StackFrame {
+	if stack := ErrorStack(err); stack != nil {
+		return stack
+	}
+	for i := 1; i < len(keysAndValues); i += 2 {
+		if e, ok := keysAndValues[i].(error); ok {
+			if stack := ErrorStack(e); stack != nil {
+				return stack
+			}
+		}
+	}
+	return stackFrom(callSite(ctx), skip)
+}
+
+// isNilPointer reports whether err is a typed nil pointer, whose methods may panic
+func isNilPointer(err error) bool {
+	v := reflect.ValueOf(err)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+func toStackFrame(frame runtime.Frame) StackFrame {
+	return StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line}
+}
+
+func isRuntimeFrame(function string) bool {
+	return strings.HasPrefix(function, "runtime.")
+}
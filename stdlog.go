@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"log"
+	"strings"
+)
+
+// levelPrefixes maps level tags found at the start of standard library log lines to levels
+var levelPrefixes = map[string]Level{
+	"TRACE":   LevelTrace,
+	"TRC":     LevelTrace,
+	"DEBUG":   LevelDebug,
+	"DBG":     LevelDebug,
+	"INFO":    LevelInfo,
+	"INF":     LevelInfo,
+	"WARN":    LevelWarn,
+	"WARNING": LevelWarn,
+	"WRN":     LevelWarn,
+	"ERROR":   LevelError,
+	"ERR":     LevelError,
+	"FATAL":   LevelFatal,
+	"FTL":     LevelFatal,
+}
+
+// stdLogWriter turns each write from a *log.Logger into an entry on a Logger
+type stdLogWriter struct {
+	logger      Logger
+	level       Level
+	parseLevels bool
+}
+
+// NewStdLogger returns a *log.Logger, e.g. for http.Server.ErrorLog, whose output is
+// written to l as entries at the given level
+func NewStdLogger(l Logger, level Level) *log.Logger {
+	return log.New(&stdLogWriter{logger: l, level: level}, "", 0)
+}
+
+// NewStdLoggerWithLevelPrefixes is like NewStdLogger but lines starting with a level tag
+// such as "[WARN]", "error:" or "DEBUG" are written at that level with the tag removed
+func NewStdLoggerWithLevelPrefixes(l Logger, level Level) *log.Logger {
+	return log.New(&stdLogWriter{logger: l, level: level, parseLevels: true}, "", 0)
+}
+
+// RedirectStdLog sends the output of the standard library log package's global logger
+// to l, at info level unless the line starts with a level tag. It returns a function
+// that restores the previous output, flags and prefix.
+func RedirectStdLog(l Logger) func() {
+	out, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+
+	log.SetOutput(&stdLogWriter{logger: l, level: LevelInfo, parseLevels: true})
+	log.SetFlags(0)
+	log.SetPrefix("")
+
+	return func() {
+		log.SetOutput(out)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\r\n")
+	level := w.level
+	if w.parseLevels {
+		level, msg = parseLevelPrefix(msg, level)
+	}
+
+	LogAt(w.logger, nil, level, msg)
+	return len(p), nil
+}
+
+// parseLevelPrefix detects a level tag at the start of msg, such as "[WARN]", "warn:" or
+// "WARN ", returning the level and the message with the tag removed
+func parseLevelPrefix(msg string, level Level) (Level, string) {
+	trimmed := strings.TrimLeft(msg, " \t")
+
+	var tag, rest string
+	if strings.HasPrefix(trimmed, "[") {
+		end := strings.IndexByte(trimmed, ']')
+		if end < 0 {
+			return level, msg
+		}
+		tag, rest = trimmed[1:end], trimmed[end+1:]
+	} else {
+		end := strings.IndexAny(trimmed, ": \t")
+		if end < 0 {
+			return level, msg
+		}
+		tag, rest = trimmed[:end], trimmed[end:]
+
+		// Without a colon only upper case tags count, so "Error opening file" is left alone
+		if strings.HasPrefix(rest, ":") {
+			rest = rest[1:]
+		} else if tag != strings.ToUpper(tag) {
+			return level, msg
+		}
+	}
+
+	lvl, ok := levelPrefixes[strings.ToUpper(tag)]
+	if !ok {
+		return level, msg
+	}
+	return lvl, strings.TrimLeft(rest, " \t")
+}
@@ -1,18 +1,23 @@
 package logtesting
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
 	"github.com/paularlott/logger"
 )
 
-// MockLogger is a logger implementation that captures log calls for testing
+// MockLogger is a logger implementation that captures log calls for testing.
+// Child loggers created with With, WithError and WithGroup record their entries
+// on the root logger, so assertions made on the root see everything logged below it.
 type MockLogger struct {
 	mu      sync.RWMutex
 	Entries []LogEntry
 	attrs   map[string]any
 	group   string
+	level   logger.Level // Minimum level captured, defaults to trace
+	root    *MockLogger  // nil for the root logger
 }
 
 // LogEntry represents a single log entry
@@ -22,6 +27,7 @@ type LogEntry struct {
 	KeysAndValues []any
 	Attrs         map[string]any
 	Group         string
+	Context       context.Context // Set for entries logged via the *Context methods
 }
 
 // New creates a new MockLogger
@@ -32,53 +38,145 @@ func New() *MockLogger {
 	}
 }
 
-func (m *MockLogger) log(level string, msg string, keysAndValues ...any) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// NewContext creates a new MockLogger and returns a copy of ctx carrying it,
+// for testing code that retrieves its logger with logger.FromContext
+func NewContext(ctx context.Context) (context.Context, *MockLogger) {
+	m := New()
+	return logger.NewContext(ctx, m), m
+}
 
-	// Copy attrs
+// store returns the logger that holds the captured entries
+func (m *MockLogger) store() *MockLogger {
+	if m.root != nil {
+		return m.root
+	}
+	return m
+}
+
+func (m *MockLogger) log(ctx context.Context, level string, msg string, keysAndValues ...any) {
+	if lvl, err := logger.ParseLevel(level); err == nil && !m.Enabled(lvl) {
+		return
+	}
+
+	// Copy attrs, evaluating lazy values now the entry is being captured
 	attrs := make(map[string]any, len(m.attrs))
 	for k, v := range m.attrs {
+		if lv, ok := v.(logger.LazyValue); ok {
+			v = lv.Value()
+		}
 		attrs[k] = v
 	}
 
-	m.Entries = append(m.Entries, LogEntry{
+	// Evaluate lazy values without modifying the caller's slice
+	copied := false
+	for i, v := range keysAndValues {
+		if lv, ok := v.(logger.LazyValue); ok {
+			if !copied {
+				keysAndValues = append([]any(nil), keysAndValues...)
+				copied = true
+			}
+			keysAndValues[i] = lv.Value()
+		}
+	}
+
+	s := m.store()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Entries = append(s.Entries, LogEntry{
 		Level:         level,
 		Message:       msg,
 		KeysAndValues: keysAndValues,
 		Attrs:         attrs,
 		Group:         m.group,
+		Context:       ctx,
 	})
 }
 
+// Enabled reports whether entries at the given level are captured
+func (m *MockLogger) Enabled(level logger.Level) bool {
+	s := m.store()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return level >= s.level
+}
+
+// SetLevel sets the minimum level captured by this logger and all of its children
+func (m *MockLogger) SetLevel(level string) error {
+	lvl, err := logger.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	s := m.store()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.level = lvl
+	return nil
+}
+
+// Level returns the minimum level captured
+func (m *MockLogger) Level() string {
+	s := m.store()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.level.String()
+}
+
+// Log captures an entry at the given level
+func (m *MockLogger) Log(ctx context.Context, level logger.Level, msg string, keysAndValues ...any) {
+	m.log(ctx, level.String(), msg, keysAndValues...)
+}
+
 func (m *MockLogger) Trace(msg string, keysAndValues ...any) {
-	m.log("trace", msg, keysAndValues...)
+	m.log(nil, "trace", msg, keysAndValues...)
 }
 
 func (m *MockLogger) Debug(msg string, keysAndValues ...any) {
-	m.log("debug", msg, keysAndValues...)
+	m.log(nil, "debug", msg, keysAndValues...)
 }
 
 func (m *MockLogger) Info(msg string, keysAndValues ...any) {
-	m.log("info", msg, keysAndValues...)
+	m.log(nil, "info", msg, keysAndValues...)
 }
 
 func (m *MockLogger) Warn(msg string, keysAndValues ...any) {
-	m.log("warn", msg, keysAndValues...)
+	m.log(nil, "warn", msg, keysAndValues...)
 }
 
 func (m *MockLogger) Error(msg string, keysAndValues ...any) {
-	m.log("error", msg, keysAndValues...)
+	m.log(nil, "error", msg, keysAndValues...)
 }
 
 func (m *MockLogger) Fatal(msg string, keysAndValues ...any) {
-	m.log("fatal", msg, keysAndValues...)
+	m.log(nil, "fatal", msg, keysAndValues...)
 }
 
-func (m *MockLogger) With(key string, value any) logger.Logger {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+func (m *MockLogger) TraceContext(ctx context.Context, msg string, keysAndValues ...any) {
+	m.log(ctx, "trace", msg, keysAndValues...)
+}
+
+func (m *MockLogger) DebugContext(ctx context.Context, msg string, keysAndValues ...any) {
+	m.log(ctx, "debug", msg, keysAndValues...)
+}
+
+func (m *MockLogger) InfoContext(ctx context.Context, msg string, keysAndValues ...any) {
+	m.log(ctx, "info", msg, keysAndValues...)
+}
 
+func (m *MockLogger) WarnContext(ctx context.Context, msg string, keysAndValues ...any) {
+	m.log(ctx, "warn", msg, keysAndValues...)
+}
+
+func (m *MockLogger) ErrorContext(ctx context.Context, msg string, keysAndValues ...any) {
+	m.log(ctx, "error", msg, keysAndValues...)
+}
+
+func (m *MockLogger) FatalContext(ctx context.Context, msg string, keysAndValues ...any) {
+	m.log(ctx, "fatal", msg, keysAndValues...)
+}
+
+func (m *MockLogger) With(key string, value any) logger.Logger {
 	newAttrs := make(map[string]any, len(m.attrs)+1)
 	for k, v := range m.attrs {
 		newAttrs[k] = v
@@ -86,10 +184,9 @@ func (m *MockLogger) With(key string, value any) logger.Logger {
 	newAttrs[key] = value
 
 	return &MockLogger{
-		Entries: m.Entries, // Share entries slice for assertions
-		attrs:   newAttrs,
-		group:   m.group,
-		mu:      sync.RWMutex{},
+		attrs: newAttrs,
+		group: m.group,
+		root:  m.store(),
 	}
 }
 
@@ -98,45 +195,44 @@ func (m *MockLogger) WithError(err error) logger.Logger {
 }
 
 func (m *MockLogger) WithGroup(group string) logger.Logger {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
 	newAttrs := make(map[string]any, len(m.attrs))
 	for k, v := range m.attrs {
 		newAttrs[k] = v
 	}
 
 	return &MockLogger{
-		Entries: m.Entries, // Share entries slice for assertions
-		attrs:   newAttrs,
-		group:   group,
-		mu:      sync.RWMutex{},
+		attrs: newAttrs,
+		group: group,
+		root:  m.store(),
 	}
 }
 
 // Reset clears all captured log entries
 func (m *MockLogger) Reset() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.Entries = make([]LogEntry, 0)
+	s := m.store()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Entries = make([]LogEntry, 0)
 }
 
 // GetEntries returns a copy of all log entries (thread-safe)
 func (m *MockLogger) GetEntries() []LogEntry {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	s := m.store()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	entries := make([]LogEntry, len(m.Entries))
-	copy(entries, m.Entries)
+	entries := make([]LogEntry, len(s.Entries))
+	copy(entries, s.Entries)
 	return entries
 }
 
 // HasEntry checks if an entry with the given level and message exists
 func (m *MockLogger) HasEntry(level, message string) bool {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	s := m.store()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	for _, entry := range m.Entries {
+	for _, entry := range s.Entries {
 		if entry.Level == level && entry.Message == message {
 			return true
 		}
@@ -146,11 +242,12 @@ func (m *MockLogger) HasEntry(level, message string) bool {
 
 // CountEntries returns the number of log entries with the given level
 func (m *MockLogger) CountEntries(level string) int {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	s := m.store()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
 	count := 0
-	for _, entry := range m.Entries {
+	for _, entry := range s.Entries {
 		if entry.Level == level {
 			count++
 		}
@@ -160,27 +257,29 @@ func (m *MockLogger) CountEntries(level string) int {
 
 // LastEntry returns the last log entry, or nil if no entries
 func (m *MockLogger) LastEntry() *LogEntry {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	s := m.store()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	if len(m.Entries) == 0 {
+	if len(s.Entries) == 0 {
 		return nil
 	}
-	entry := m.Entries[len(m.Entries)-1]
+	entry := s.Entries[len(s.Entries)-1]
 	return &entry
 }
 
 // String returns a human-readable representation of all log entries
 func (m *MockLogger) String() string {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	s := m.store()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	if len(m.Entries) == 0 {
+	if len(s.Entries) == 0 {
 		return "No log entries"
 	}
 
-	result := fmt.Sprintf("Log entries (%d):\n", len(m.Entries))
-	for i, entry := range m.Entries {
+	result := fmt.Sprintf("Log entries (%d):\n", len(s.Entries))
+	for i, entry := range s.Entries {
 		result += fmt.Sprintf("  [%d] %s: %s", i, entry.Level, entry.Message)
 		if entry.Group != "" {
 			result += fmt.Sprintf(" [group=%s]", entry.Group)
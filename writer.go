@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+)
+
+const defaultMaxLineLength = 64 * 1024
+
+// LineWriter is an io.WriteCloser that turns each line written to it into an entry on
+// a Logger, e.g. to log the output of a subprocess. Partial lines are buffered until
+// their newline arrives or the writer is closed. Configure the exported fields before
+// the first Write.
+type LineWriter struct {
+	MaxLineLength int  // Longer lines are truncated and flagged with truncated=true, defaults to 64KiB
+	ParseJSON     bool // Lift the fields of JSON object lines into keysAndValues
+	ParseLevels   bool // Use a leading level tag such as "[WARN]" as the level, see NewStdLoggerWithLevelPrefixes
+
+	logger    Logger
+	level     Level
+	mu        sync.Mutex
+	buf       []byte
+	truncated bool // Discarding the rest of an overlong line
+	closed    bool
+}
+
+// Writer returns a LineWriter that writes each line to l at the given level
+func Writer(l Logger, level Level) *LineWriter {
+	return &LineWriter{
+		logger: l,
+		level:  level,
+	}
+}
+
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	maxLen := w.MaxLineLength
+	if maxLen <= 0 {
+		maxLen = defaultMaxLineLength
+	}
+
+	data := p
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			w.buffer(data, maxLen)
+			break
+		}
+
+		w.buffer(data[:i], maxLen)
+		w.emit()
+		data = data[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Close writes any buffered partial line
+func (w *LineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if len(w.buf) > 0 || w.truncated {
+		w.emit()
+	}
+	return nil
+}
+
+// buffer appends to the current line, dropping anything beyond maxLen
+func (w *LineWriter) buffer(data []byte, maxLen int) {
+	if w.truncated {
+		return
+	}
+	if room := maxLen - len(w.buf); len(data) > room {
+		data = data[:room]
+		w.truncated = true
+	}
+	w.buf = append(w.buf, data...)
+}
+
+// emit writes the buffered line as an entry and resets the buffer
+func (w *LineWriter) emit() {
+	line := string(bytes.TrimRight(w.buf, "\r"))
+	truncated := w.truncated
+	w.buf = w.buf[:0]
+	w.truncated = false
+
+	level := w.level
+	msg := line
+	var keysAndValues []any
+
+	if w.ParseJSON && !truncated {
+		if lvl, jsonMsg, kv, ok := parseJSONLine(line, level); ok {
+			level, msg, keysAndValues = lvl, jsonMsg, kv
+		}
+	}
+	if w.ParseLevels && keysAndValues == nil {
+		level, msg = parseLevelPrefix(msg, level)
+	}
+	if truncated {
+		keysAndValues = append(keysAndValues, "truncated", true)
+	}
+
+	LogAt(w.logger, nil, level, msg, keysAndValues...)
+}
+
+// parseJSONLine lifts the fields of a JSON object into key-value pairs sorted by key,
+// using "msg" or "message" as the message and "level" as the level when recognised
+func parseJSONLine(line string, level Level) (Level, string, []any, bool) {
+	trimmed := bytes.TrimSpace([]byte(line))
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return level, line, nil, false
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return level, line, nil, false
+	}
+
+	var msg string
+	for _, key := range []string{"msg", "message"} {
+		if s, ok := fields[key].(string); ok {
+			msg = s
+			delete(fields, key)
+			break
+		}
+	}
+	if s, ok := fields["level"].(string); ok {
+		if lvl, err := ParseLevel(s); err == nil {
+			level = lvl
+			delete(fields, "level")
+		}
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	keysAndValues := make([]any, 0, len(keys)*2)
+	for _, key := range keys {
+		keysAndValues = append(keysAndValues, key, fields[key])
+	}
+	return level, msg, keysAndValues, true
+}
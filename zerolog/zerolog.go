@@ -1,18 +1,63 @@
 package logzerolog
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/paularlott/logger"
 	"github.com/rs/zerolog"
 )
 
-// ZerologLogger wraps zerolog.Logger to implement the logger.Logger interface
+// ZerologLogger wraps zerolog.Logger to implement the logger.Logger and logger.ContextLogger interfaces
 type ZerologLogger struct {
 	logger         zerolog.Logger
+	levels         *levels // Shared by the root logger and all of its children
+	group          string
 	groupFieldName string
+	lazy           []any                // Lazy key-value pairs from With, added to each entry written
+	source         *logger.SourceConfig // nil unless AddCaller or StackTrace is set
+	err            error                // From WithError, checked for a recorded stack
+}
+
+// levels holds the minimum level and any per-group overrides, shared by a root
+// logger and all of its children
+type levels struct {
+	global atomic.Int32
+	mu     sync.Mutex                               // Serialises updates to groups
+	groups atomic.Pointer[map[string]zerolog.Level] // Replaced on write, never modified
+}
+
+// forGroup returns the minimum level for the given group
+func (lv *levels) forGroup(group string) zerolog.Level {
+	if group != "" {
+		if groups := lv.groups.Load(); groups != nil {
+			if level, ok := (*groups)[group]; ok {
+				return level
+			}
+		}
+	}
+	return zerolog.Level(lv.global.Load())
+}
+
+// update replaces the group overrides with a modified copy
+func (lv *levels) update(fn func(groups map[string]zerolog.Level)) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	groups := make(map[string]zerolog.Level)
+	if old := lv.groups.Load(); old != nil {
+		for k, v := range *old {
+			groups[k] = v
+		}
+	}
+	fn(groups)
+	lv.groups.Store(&groups)
 }
 
 // Config for creating a new ZerologLogger
@@ -21,13 +66,44 @@ type Config struct {
 	Format         string    // "console" or "json"
 	Writer         io.Writer // Output writer, defaults to os.Stdout
 	GroupFieldName string    // Field name for groups, defaults to "_group"
+	NoColor        bool      // Disable colors in console output
+	AddCaller      bool      // Add the file and line of the log call to each entry
+	CallerSkip     int       // Extra frames to skip when the application wraps the logger
+	StackTrace     bool      // Add a stack trace to error and fatal entries
+
+	// LevelOverrides sets the level for loggers created with WithGroup, keyed by group name,
+	// e.g. {"database": "trace", "http": "warn"}. See logger.ParseLevelOverrides for the string form.
+	LevelOverrides map[string]string
+}
+
+func init() {
+	logger.Register("zerolog", func(cfg logger.Config) (logger.Logger, error) {
+		return NewWithError(ConfigFrom(cfg))
+	})
 }
 
-// New creates a new ZerologLogger with the given configuration
+// ConfigFrom converts a backend-independent logger.Config, e.g. from logger.ConfigFromEnv
+func ConfigFrom(cfg logger.Config) Config {
+	return Config{
+		Level:          cfg.Level,
+		Format:         cfg.Format,
+		Writer:         cfg.Writer,
+		GroupFieldName: cfg.GroupFieldName,
+		NoColor:        cfg.NoColor,
+		AddCaller:      cfg.AddCaller,
+		CallerSkip:     cfg.CallerSkip,
+		StackTrace:     cfg.StackTrace,
+		LevelOverrides: cfg.LevelOverrides,
+	}
+}
+
+// New creates a new ZerologLogger with the given configuration. Unknown levels fall back
+// to info and unknown formats to console, use NewWithError to reject them instead.
 func New(cfg Config) logger.Logger {
 	if cfg.Writer == nil {
 		cfg.Writer = os.Stdout
 	}
+	cfg.Format = strings.ToLower(cfg.Format)
 	if cfg.Format == "" {
 		cfg.Format = "console"
 	}
@@ -41,102 +117,381 @@ func New(cfg Config) logger.Logger {
 	var zlog zerolog.Logger
 
 	// Configure output format
-	if cfg.Format == "console" {
+	if cfg.Format == "json" {
+		zlog = zerolog.New(cfg.Writer).With().Timestamp().Logger()
+	} else {
 		output := zerolog.ConsoleWriter{
 			Out:        cfg.Writer,
 			TimeFormat: "02 Jan 06 15:04 MST",
+			NoColor:    cfg.NoColor,
+		}
+		if cfg.AddCaller {
+			// Show the caller after the message as caller=file:line, like the other fields
+			output.PartsOrder = []string{
+				zerolog.TimestampFieldName,
+				zerolog.LevelFieldName,
+				zerolog.MessageFieldName,
+				logger.CallerKey,
+			}
+			output.FormatCaller = formatCaller(cfg.NoColor)
+		}
+		if cfg.StackTrace {
+			// Show the stack below the line rather than as a field
+			output.FieldsExclude = []string{logger.StackKey}
+			output.FormatExtra = formatStack(cfg.NoColor)
 		}
 		zlog = zerolog.New(output).With().Timestamp().Logger()
-	} else {
-		zlog = zerolog.New(cfg.Writer).With().Timestamp().Logger()
 	}
 
-	// Set log level
-	level := parseLevel(cfg.Level)
-	zlog = zlog.Level(level)
+	// Filtering is done against the shared level so it can be changed at runtime
+	zlog = zlog.Level(zerolog.TraceLevel)
+	levels := &levels{}
+	levels.global.Store(int32(parseLevel(cfg.Level)))
+	if len(cfg.LevelOverrides) > 0 {
+		levels.update(func(groups map[string]zerolog.Level) {
+			for group, level := range cfg.LevelOverrides {
+				groups[group] = parseLevel(level)
+			}
+		})
+	}
+
+	var source *logger.SourceConfig
+	if cfg.AddCaller || cfg.StackTrace {
+		source = &logger.SourceConfig{
+			Caller: cfg.AddCaller,
+			Stack:  cfg.StackTrace,
+			Skip:   cfg.CallerSkip,
+			JSON:   cfg.Format == "json",
+		}
+	}
 
 	return &ZerologLogger{
 		logger:         zlog,
+		levels:         levels,
 		groupFieldName: cfg.GroupFieldName,
+		source:         source,
 	}
 }
 
+// formatCaller renders the caller part of console output in the same style as fields
+func formatCaller(noColor bool) zerolog.Formatter {
+	return func(i any) string {
+		c, _ := i.(string)
+		if c == "" {
+			return ""
+		}
+		if noColor {
+			return logger.CallerKey + "=" + c
+		}
+		return "\x1b[36m" + logger.CallerKey + "=\x1b[0m" + c
+	}
+}
+
+// formatStack renders the stack of an entry below its line in console output, each
+// frame as an indented function name followed by its file and line
+func formatStack(noColor bool) func(map[string]any, *bytes.Buffer) error {
+	return func(evt map[string]any, buf *bytes.Buffer) error {
+		frames, _ := evt[logger.StackKey].([]any)
+		for _, f := range frames {
+			frame, ok := f.(map[string]any)
+			if !ok {
+				continue
+			}
+			location := fmt.Sprintf("%v:%v", frame["file"], frame["line"])
+			if !noColor {
+				location = "\x1b[90m" + location + "\x1b[0m"
+			}
+			fmt.Fprintf(buf, "\n    %v\n        %s", frame["function"], location)
+		}
+		return nil
+	}
+}
+
+// NewWithError creates a new ZerologLogger like New, but returns an error for an unknown
+// level, format or level override instead of falling back to the defaults
+func NewWithError(cfg Config) (logger.Logger, error) {
+	if cfg.Level != "" {
+		if _, err := ParseLevel(cfg.Level); err != nil {
+			return nil, err
+		}
+	}
+	switch strings.ToLower(cfg.Format) {
+	case "", "console", "json":
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected console or json", cfg.Format)
+	}
+	for group, level := range cfg.LevelOverrides {
+		if _, err := ParseLevel(level); err != nil {
+			return nil, fmt.Errorf("level override for group %q: %w", group, err)
+		}
+	}
+	return New(cfg), nil
+}
+
+// ParseLevel converts a level name such as "debug" to a zerolog.Level
+func ParseLevel(level string) (zerolog.Level, error) {
+	if l, ok := lookupLevel(level); ok {
+		return l, nil
+	}
+	return zerolog.InfoLevel, fmt.Errorf("unknown log level %q", level)
+}
+
 func parseLevel(level string) zerolog.Level {
+	if l, ok := lookupLevel(level); ok {
+		return l
+	}
+	return zerolog.InfoLevel
+}
+
+func lookupLevel(level string) (zerolog.Level, bool) {
 	switch strings.ToLower(level) {
 	case "trace":
-		return zerolog.TraceLevel
+		return zerolog.TraceLevel, true
 	case "debug":
-		return zerolog.DebugLevel
+		return zerolog.DebugLevel, true
 	case "info":
-		return zerolog.InfoLevel
+		return zerolog.InfoLevel, true
 	case "warn", "warning":
-		return zerolog.WarnLevel
+		return zerolog.WarnLevel, true
 	case "error":
-		return zerolog.ErrorLevel
+		return zerolog.ErrorLevel, true
 	case "fatal":
-		return zerolog.FatalLevel
-	case "panic":
-		return zerolog.PanicLevel
+		return zerolog.FatalLevel, true
 	default:
+		return zerolog.InfoLevel, false
+	}
+}
+
+// SetLevel changes the minimum level at runtime, affecting this logger, its parent and all children
+func (l *ZerologLogger) SetLevel(level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.levels.global.Store(int32(lvl))
+	return nil
+}
+
+// Level returns the current minimum level
+func (l *ZerologLogger) Level() string {
+	return zerolog.Level(l.levels.global.Load()).String()
+}
+
+// SetGroupLevel overrides the minimum level for loggers created with WithGroup(group)
+func (l *ZerologLogger) SetGroupLevel(group, level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.levels.update(func(groups map[string]zerolog.Level) {
+		groups[group] = lvl
+	})
+	return nil
+}
+
+// ClearGroupLevel removes the level override for the group
+func (l *ZerologLogger) ClearGroupLevel(group string) {
+	l.levels.update(func(groups map[string]zerolog.Level) {
+		delete(groups, group)
+	})
+}
+
+// GroupLevels returns the current per-group level overrides
+func (l *ZerologLogger) GroupLevels() map[string]string {
+	result := make(map[string]string)
+	if groups := l.levels.groups.Load(); groups != nil {
+		for group, level := range *groups {
+			result[group] = level.String()
+		}
+	}
+	return result
+}
+
+// Enabled reports whether an entry at the given level would be written
+func (l *ZerologLogger) Enabled(level logger.Level) bool {
+	lvl := toZerologLevel(level)
+	return lvl >= l.levels.forGroup(l.group) && lvl >= l.logger.GetLevel() && lvl >= zerolog.GlobalLevel()
+}
+
+// Log writes an entry at the given level, without exiting for logger.LevelFatal
+func (l *ZerologLogger) Log(ctx context.Context, level logger.Level, msg string, keysAndValues ...any) {
+	l.log(ctx, toZerologLevel(level), msg, keysAndValues...)
+}
+
+func toZerologLevel(level logger.Level) zerolog.Level {
+	switch level {
+	case logger.LevelTrace:
+		return zerolog.TraceLevel
+	case logger.LevelDebug:
+		return zerolog.DebugLevel
+	case logger.LevelInfo:
 		return zerolog.InfoLevel
+	case logger.LevelWarn:
+		return zerolog.WarnLevel
+	case logger.LevelError:
+		return zerolog.ErrorLevel
+	default:
+		return zerolog.FatalLevel
+	}
+}
+
+// fromZerologLevel maps a zerolog level to the nearest logger.Level
+func fromZerologLevel(level zerolog.Level) logger.Level {
+	switch {
+	case level <= zerolog.TraceLevel:
+		return logger.LevelTrace
+	case level == zerolog.DebugLevel:
+		return logger.LevelDebug
+	case level == zerolog.InfoLevel:
+		return logger.LevelInfo
+	case level == zerolog.WarnLevel:
+		return logger.LevelWarn
+	case level == zerolog.ErrorLevel:
+		return logger.LevelError
+	default:
+		return logger.LevelFatal
+	}
+}
+
+// event starts a new event at the given level, returning nil if the level is disabled
+func (l *ZerologLogger) event(level zerolog.Level) *zerolog.Event {
+	if level < l.levels.forGroup(l.group) {
+		return nil
 	}
+	return l.logger.WithLevel(level)
 }
 
 func (l *ZerologLogger) Trace(msg string, keysAndValues ...any) {
-	l.log(l.logger.Trace(), msg, keysAndValues...)
+	l.log(nil, zerolog.TraceLevel, msg, keysAndValues...)
 }
 
 func (l *ZerologLogger) Debug(msg string, keysAndValues ...any) {
-	l.log(l.logger.Debug(), msg, keysAndValues...)
+	l.log(nil, zerolog.DebugLevel, msg, keysAndValues...)
 }
 
 func (l *ZerologLogger) Info(msg string, keysAndValues ...any) {
-	l.log(l.logger.Info(), msg, keysAndValues...)
+	l.log(nil, zerolog.InfoLevel, msg, keysAndValues...)
 }
 
 func (l *ZerologLogger) Warn(msg string, keysAndValues ...any) {
-	l.log(l.logger.Warn(), msg, keysAndValues...)
+	l.log(nil, zerolog.WarnLevel, msg, keysAndValues...)
 }
 
 func (l *ZerologLogger) Error(msg string, keysAndValues ...any) {
-	l.log(l.logger.Error(), msg, keysAndValues...)
+	l.log(nil, zerolog.ErrorLevel, msg, keysAndValues...)
 }
 
 func (l *ZerologLogger) Fatal(msg string, keysAndValues ...any) {
-	l.log(l.logger.Fatal(), msg, keysAndValues...)
+	l.log(nil, zerolog.FatalLevel, msg, keysAndValues...)
+	logger.Exit(logger.ExitCode(keysAndValues))
+}
+
+func (l *ZerologLogger) TraceContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.log(ctx, zerolog.TraceLevel, msg, keysAndValues...)
+}
+
+func (l *ZerologLogger) DebugContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.log(ctx, zerolog.DebugLevel, msg, keysAndValues...)
+}
+
+func (l *ZerologLogger) InfoContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.log(ctx, zerolog.InfoLevel, msg, keysAndValues...)
+}
+
+func (l *ZerologLogger) WarnContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.log(ctx, zerolog.WarnLevel, msg, keysAndValues...)
+}
+
+func (l *ZerologLogger) ErrorContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.log(ctx, zerolog.ErrorLevel, msg, keysAndValues...)
+}
+
+func (l *ZerologLogger) FatalContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.log(ctx, zerolog.FatalLevel, msg, keysAndValues...)
+	logger.Exit(logger.ExitCode(keysAndValues))
 }
 
-func (l *ZerologLogger) log(event *zerolog.Event, msg string, keysAndValues ...any) {
-	// Add key-value pairs
+func (l *ZerologLogger) log(ctx context.Context, level zerolog.Level, msg string, keysAndValues ...any) {
+	event := l.event(level)
+	if event == nil {
+		return
+	}
+	if ctx != nil {
+		event.Ctx(ctx)
+	}
+
+	// Add key-value pairs, lazy values from With first
+	addFields(event, l.lazy)
+	addFields(event, keysAndValues)
+	if l.source != nil {
+		addFields(event, l.source.Fields(ctx, fromZerologLevel(level), l.err, keysAndValues))
+	}
+	event.Msg(msg)
+}
+
+func addFields(event *zerolog.Event, keysAndValues []any) {
 	for i := 0; i < len(keysAndValues); i += 2 {
 		if i+1 < len(keysAndValues) {
 			key, ok := keysAndValues[i].(string)
 			if !ok {
 				continue
 			}
-			event.Interface(key, keysAndValues[i+1])
+			value := keysAndValues[i+1]
+			if v, ok := value.(logger.LazyValue); ok {
+				value = v.Value()
+			}
+			event.Interface(key, value)
 		}
 	}
-	event.Msg(msg)
 }
 
 func (l *ZerologLogger) With(key string, value any) logger.Logger {
+	if _, ok := value.(logger.LazyValue); ok {
+		// Context fields are rendered immediately, so keep lazy values back until an entry is written
+		lazy := make([]any, len(l.lazy), len(l.lazy)+2)
+		copy(lazy, l.lazy)
+		return &ZerologLogger{
+			logger:         l.logger,
+			levels:         l.levels,
+			group:          l.group,
+			groupFieldName: l.groupFieldName,
+			lazy:           append(lazy, key, value),
+			source:         l.source,
+			err:            l.err,
+		}
+	}
+
 	return &ZerologLogger{
 		logger:         l.logger.With().Interface(key, value).Logger(),
+		levels:         l.levels,
+		group:          l.group,
 		groupFieldName: l.groupFieldName,
+		lazy:           l.lazy,
+		source:         l.source,
+		err:            l.err,
 	}
 }
 
 func (l *ZerologLogger) WithError(err error) logger.Logger {
 	return &ZerologLogger{
 		logger:         l.logger.With().Err(err).Logger(),
+		levels:         l.levels,
+		group:          l.group,
 		groupFieldName: l.groupFieldName,
+		lazy:           l.lazy,
+		source:         l.source,
+		err:            err,
 	}
 }
 
 func (l *ZerologLogger) WithGroup(group string) logger.Logger {
 	return &ZerologLogger{
 		logger:         l.logger.With().Str(l.groupFieldName, group).Logger(),
+		levels:         l.levels,
+		group:          group,
 		groupFieldName: l.groupFieldName,
+		lazy:           l.lazy,
+		source:         l.source,
+		err:            l.err,
 	}
 }